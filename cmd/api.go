@@ -10,6 +10,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/psds-microservice/notification-service/internal/application"
 	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +28,9 @@ func runAPI(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("config: %w", err)
 	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
 	app, err := application.NewAPI(cfg)
 	if err != nil {
 		return err
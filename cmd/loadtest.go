@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/loadtest"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/pkg/constants"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Open N WebSocket clients, broadcast via gRPC at a given rate and report latency, drops and memory",
+	Example: `  notification-service loadtest --clients 5000 --regions eu,us --roles operator,client --sessions 100 --rate 50 --duration 1m
+  notification-service loadtest --clients 1000 --target user --rate 500 --payload-size 1024 --batch-ms 50`,
+	Args: cobra.NoArgs,
+	RunE: runLoadtest,
+}
+
+func init() {
+	f := loadtestCmd.Flags()
+	f.String("url", "", "service base URL (default ws://localhost:APP_PORT)")
+	f.String("addr", "", "gRPC address of the service (default localhost:GRPC_PORT)")
+	f.String("metrics-url", "", "service metrics URL for memory and drops (default /metrics at --url; \"-\" to disable)")
+	f.String("token", "", "bearer token sent in the Authorization header of WebSocket clients")
+	f.IntP("clients", "n", 100, "number of WebSocket clients")
+	f.Int("connect-rate", 500, "new connections per second")
+	f.StringSlice("regions", nil, "regions assigned to clients round-robin")
+	f.StringSlice("roles", nil, "roles assigned to clients round-robin")
+	f.Int("sessions", 0, "number of sessions; clients subscribe to them round-robin")
+	f.StringSlice("target", nil, "broadcast targets in turn: session, region, role, user (default: those clients have)")
+	f.Float64("rate", 10, "broadcasts per second")
+	f.Duration("duration", 30*time.Second, "how long to broadcast")
+	f.Duration("drain", 5*time.Second, "how long to wait for deliveries after the last broadcast")
+	f.Int("payload-size", 0, "extra payload bytes per broadcast")
+	f.Int("batch-ms", 0, "client coalescing window (?batch_ms)")
+	f.Bool("compression", false, "negotiate permessage-deflate")
+}
+
+func runLoadtest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	f := cmd.Flags()
+	var lc loadtest.Config
+	lc.URL, _ = f.GetString("url")
+	if lc.URL == "" {
+		lc.URL = "ws://localhost:" + cfg.HTTPPort
+	}
+	u, err := url.Parse(lc.URL)
+	if err != nil {
+		return fmt.Errorf("--url: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return fmt.Errorf("--url: unsupported scheme %q", u.Scheme)
+	}
+	lc.URL = u.String()
+	lc.MetricsURL, _ = f.GetString("metrics-url")
+	switch lc.MetricsURL {
+	case "":
+		m := *u
+		m.Scheme = strings.Replace(m.Scheme, "ws", "http", 1)
+		m.Path = strings.TrimRight(m.Path, "/") + constants.PathMetrics
+		lc.MetricsURL = m.String()
+	case "-":
+		lc.MetricsURL = ""
+	}
+	lc.Token, _ = f.GetString("token")
+	lc.Clients, _ = f.GetInt("clients")
+	lc.ConnectRate, _ = f.GetInt("connect-rate")
+	lc.Regions, _ = f.GetStringSlice("regions")
+	lc.Roles, _ = f.GetStringSlice("roles")
+	lc.Sessions, _ = f.GetInt("sessions")
+	lc.Targets, _ = f.GetStringSlice("target")
+	lc.Rate, _ = f.GetFloat64("rate")
+	lc.Duration, _ = f.GetDuration("duration")
+	lc.Drain, _ = f.GetDuration("drain")
+	lc.PayloadSize, _ = f.GetInt("payload-size")
+	lc.BatchMS, _ = f.GetInt("batch-ms")
+	lc.Compression, _ = f.GetBool("compression")
+
+	addr, _ := f.GetString("addr")
+	if addr == "" {
+		addr = "localhost:" + cfg.GRPCPort
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(cmd.ErrOrStderr(), "connecting %d clients to %s, broadcasting %.1f/s for %s via %s\n",
+		lc.Clients, lc.URL, lc.Rate, lc.Duration, addr)
+	rep, err := loadtest.Run(ctx, lc, notification_service.NewNotificationServiceClient(conn))
+	rep.Print(cmd.OutOrStdout())
+	return err
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/psds-microservice/notification-service/database/migrations"
+	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/internal/migrate"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply embedded database migrations",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "Apply N pending migrations (all by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: withMigrator(func(ctx context.Context, cmd *cobra.Command, m *migrate.Migrator, args []string) error {
+		n, err := stepsArg(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := m.Up(ctx, n)
+		for _, mig := range applied {
+			fmt.Fprintf(cmd.OutOrStdout(), "up %d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no change")
+		}
+		return err
+	}),
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Revert N last migrations (1 by default, --all for every migration)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: withMigrator(func(ctx context.Context, cmd *cobra.Command, m *migrate.Migrator, args []string) error {
+		n, err := stepsArg(args, 1)
+		if err != nil {
+			return err
+		}
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			if len(args) > 0 {
+				return fmt.Errorf("N and --all are mutually exclusive")
+			}
+			n = 0
+		}
+		reverted, err := m.Down(ctx, n)
+		for _, mig := range reverted {
+			fmt.Fprintf(cmd.OutOrStdout(), "down %d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no change")
+		}
+		return err
+	}),
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List migrations and whether they are applied",
+	Args:  cobra.NoArgs,
+	RunE: withMigrator(func(ctx context.Context, cmd *cobra.Command, m *migrate.Migrator, args []string) error {
+		list, version, dirty, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%-8s %06d_%s\n", state, s.Version, s.Name)
+		}
+		printVersion(cmd, version, dirty)
+		return nil
+	}),
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the current schema version",
+	Args:  cobra.NoArgs,
+	RunE: withMigrator(func(ctx context.Context, cmd *cobra.Command, m *migrate.Migrator, args []string) error {
+		version, dirty, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		printVersion(cmd, version, dirty)
+		return nil
+	}),
+}
+
+func init() {
+	migrateDownCmd.Flags().Bool("all", false, "revert all migrations")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateVersionCmd)
+}
+
+// withMigrator загружает конфиг, подключается к DB_* и передаёт мигратор в fn.
+func withMigrator(fn func(ctx context.Context, cmd *cobra.Command, m *migrate.Migrator, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		db, err := repository.NewPool(ctx, cfg.DatabaseURL())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		m, err := migrate.New(db, migrations.FS)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, cmd, m, args)
+	}
+}
+
+func stepsArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("N must be a positive number, got %q", args[0])
+	}
+	return n, nil
+}
+
+func printVersion(cmd *cobra.Command, version uint64, dirty bool) {
+	switch {
+	case version == 0:
+		fmt.Fprintln(cmd.OutOrStdout(), "version: none")
+	case dirty:
+		fmt.Fprintf(cmd.OutOrStdout(), "version: %d (dirty)\n", version)
+	default:
+		fmt.Fprintf(cmd.OutOrStdout(), "version: %d\n", version)
+	}
+}
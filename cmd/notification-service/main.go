@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // IANA-зоны для окон тишины, если в образе нет /usr/share/zoneinfo
 
 	_ "github.com/psds-microservice/infra" // для go mod vendor (proto-build)
 	"github.com/psds-microservice/notification-service/cmd"
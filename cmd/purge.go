@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/psds-microservice/notification-service/internal/application"
+	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete or archive notifications older than RETENTION_POLICY",
+	RunE:  runPurge,
+}
+
+func init() {
+	f := purgeCmd.Flags()
+	f.Bool("dry-run", false, "only count rows that would be purged")
+	f.String("policy", "", "retention policy, overrides RETENTION_POLICY (e.g. \"*:90d,psds.session.created:30d\")")
+	f.Bool("archive", false, "move purged rows to notification_archive (default RETENTION_ARCHIVE)")
+	f.Int("batch-size", 0, "rows per batch (default RETENTION_BATCH_SIZE)")
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	f := cmd.Flags()
+	if f.Changed("policy") {
+		cfg.Retention.Policy, _ = f.GetString("policy")
+	}
+	if f.Changed("archive") {
+		cfg.Retention.Archive, _ = f.GetBool("archive")
+	}
+	if f.Changed("batch-size") {
+		cfg.Retention.BatchSize, _ = f.GetInt("batch-size")
+	}
+	dryRun, _ := f.GetBool("dry-run")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	db, err := repository.NewPool(ctx, cfg.DatabaseURL())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	purger, err := application.NewPurger(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	run, verb := purger.Purge, "purged"
+	if dryRun {
+		run, verb = purger.DryRun, "would purge"
+	}
+	res, err := run(ctx)
+	// При ошибке печатается то, что успело выполниться.
+	for _, table := range repository.RetentionTables {
+		if n, ok := res[table]; ok {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s %d rows\n", table, verb, n)
+		}
+	}
+	return err
+}
@@ -15,5 +15,5 @@ func Execute() error {
 }
 
 func init() {
-	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(apiCmd, loadtestCmd, migrateCmd, purgeCmd, replayCmd, sendCmd, tailCmd)
 }
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/kafka"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a test notification through the running service (gRPC) or Kafka",
+	Example: `  notification-service send --event psds.session.created --session 6f1c... --payload '{"source":"qa"}'
+  notification-service send --event psds.operator.assigned --user 2b7e... --user 9a01... --priority high
+  notification-service send --event psds.session.ended --region eu --role operator --payload-file payload.json --via-kafka`,
+	Args: cobra.NoArgs,
+	RunE: runSend,
+}
+
+func init() {
+	f := sendCmd.Flags()
+	f.StringP("event", "e", "", "event type (required)")
+	f.String("session", "", "session_id: subscribers of the session")
+	f.StringSlice("user", nil, "user_id of a recipient (repeatable)")
+	f.StringSlice("region", nil, "region of recipients (repeatable)")
+	f.StringSlice("role", nil, "role of recipients (repeatable)")
+	f.String("priority", "", "low, normal, high or critical")
+	f.String("payload", "", "payload JSON object")
+	f.String("payload-file", "", "file with payload JSON object (- for stdin)")
+	f.String("addr", "", "gRPC address of the service (default localhost:GRPC_PORT)")
+	f.Duration("timeout", 10*time.Second, "request timeout")
+	f.Bool("via-kafka", false, "produce the event envelope to Kafka instead of calling gRPC")
+	f.String("topic", "", "Kafka topic (default: the event if it is in KAFKA_TOPICS, else the first of KAFKA_TOPICS)")
+	f.StringSlice("brokers", nil, "Kafka brokers (default KAFKA_BROKERS)")
+	_ = sendCmd.MarkFlagRequired("event")
+	sendCmd.MarkFlagsMutuallyExclusive("payload", "payload-file")
+}
+
+func runSend(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	f := cmd.Flags()
+	msg := routing.Message{}
+	msg.Event, _ = f.GetString("event")
+	msg.SessionID, _ = f.GetString("session")
+	msg.UserIDs, _ = f.GetStringSlice("user")
+	msg.Regions, _ = f.GetStringSlice("region")
+	msg.Roles, _ = f.GetStringSlice("role")
+	msg.Priority, _ = f.GetString("priority")
+	if msg.SessionID == "" && len(msg.UserIDs) == 0 && len(msg.Regions) == 0 && len(msg.Roles) == 0 {
+		return errors.New("one of --session, --user, --region or --role is required")
+	}
+	for _, id := range append([]string{msg.SessionID}, msg.UserIDs...) {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return fmt.Errorf("invalid id %q: %w", id, err)
+		}
+	}
+	if msg.Payload, err = readPayload(cmd); err != nil {
+		return err
+	}
+
+	timeout, _ := f.GetDuration("timeout")
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	if viaKafka, _ := f.GetBool("via-kafka"); viaKafka {
+		return sendKafka(ctx, cmd, cfg, msg)
+	}
+	return sendGRPC(ctx, cmd, cfg, msg)
+}
+
+// readPayload читает payload из --payload или --payload-file; это должен быть JSON-объект.
+func readPayload(cmd *cobra.Command) (json.RawMessage, error) {
+	raw, _ := cmd.Flags().GetString("payload")
+	data := []byte(raw)
+	if path, _ := cmd.Flags().GetString("payload-file"); path != "" {
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("payload: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("payload must be a JSON object: %w", err)
+	}
+	return json.RawMessage(data), nil
+}
+
+func sendGRPC(ctx context.Context, cmd *cobra.Command, cfg *config.Config, msg routing.Message) error {
+	addr, _ := cmd.Flags().GetString("addr")
+	if addr == "" {
+		addr = "localhost:" + cfg.GRPCPort
+	}
+	req := &notification_service.SendNotificationRequest{
+		Event:     msg.Event,
+		SessionId: msg.SessionID,
+		UserIds:   msg.UserIDs,
+		Regions:   msg.Regions,
+		Roles:     msg.Roles,
+		Priority:  msg.Priority,
+	}
+	if msg.Payload != nil {
+		req.Payload = &structpb.Struct{}
+		if err := req.Payload.UnmarshalJSON(msg.Payload); err != nil {
+			return fmt.Errorf("payload: %w", err)
+		}
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := notification_service.NewNotificationServiceClient(conn).SendNotification(ctx, req); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "sent %s via gRPC %s\n", msg.Event, addr)
+	return nil
+}
+
+func sendKafka(ctx context.Context, cmd *cobra.Command, cfg *config.Config, msg routing.Message) error {
+	brokers, _ := cmd.Flags().GetStringSlice("brokers")
+	if len(brokers) == 0 {
+		brokers = cfg.KafkaBrokers
+	}
+	topic, _ := cmd.Flags().GetString("topic")
+	if topic == "" {
+		switch {
+		case slices.Contains(cfg.KafkaTopics, msg.Event):
+			topic = msg.Event
+		case len(cfg.KafkaTopics) > 0:
+			topic = cfg.KafkaTopics[0]
+		default:
+			return errors.New("--topic is required: KAFKA_TOPICS is empty")
+		}
+	}
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	// Ключ — сессия или первый получатель: события одного адресата попадают в одну партицию.
+	var key []byte
+	switch {
+	case msg.SessionID != "":
+		key = []byte(msg.SessionID)
+	case len(msg.UserIDs) > 0:
+		key = []byte(msg.UserIDs[0])
+	}
+	if err := kafka.Publish(ctx, brokers, topic, key, value); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "sent %s to Kafka topic %s\n", msg.Event, topic)
+	return nil
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/jsonpath"
+	"github.com/spf13/cobra"
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail USER_ID",
+	Short: "Connect to /ws/notify/:user_id and print incoming frames",
+	Example: `  notification-service tail 2b7e... --region eu --roles operator --session 6f1c...
+  notification-service tail 2b7e... --filter '.event == psds.session.created' --select .payload`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTail,
+}
+
+func init() {
+	f := tailCmd.Flags()
+	f.String("url", "", "service base URL (default ws://localhost:APP_PORT)")
+	f.String("region", "", "client region (?region=)")
+	f.StringSlice("roles", nil, "client roles (?roles=)")
+	f.String("locale", "", "notification language (?locale=)")
+	f.String("token", "", "bearer token sent in the Authorization header")
+	f.StringSlice("session", nil, "session_id to subscribe to (repeatable)")
+	f.StringArray("filter", nil, "print only messages matching a jq-like condition, e.g. '.event == \"x\"' (repeatable, all must match)")
+	f.String("select", "", "print only the value at a jq-like path, e.g. .payload")
+	f.Bool("compact", false, "print each message on one line")
+}
+
+func runTail(cmd *cobra.Command, args []string) error {
+	userID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	f := cmd.Flags()
+	var filters []jsonpath.Condition
+	exprs, _ := f.GetStringArray("filter")
+	for _, expr := range exprs {
+		c, err := jsonpath.ParseCondition(expr)
+		if err != nil {
+			return fmt.Errorf("--filter: %w", err)
+		}
+		filters = append(filters, c)
+	}
+	var sel jsonpath.Path
+	if expr, _ := f.GetString("select"); expr != "" {
+		if sel, err = jsonpath.Parse(expr); err != nil {
+			return fmt.Errorf("--select: %w", err)
+		}
+	}
+	sessions, _ := f.GetStringSlice("session")
+	for _, sid := range sessions {
+		if _, err := uuid.Parse(sid); err != nil {
+			return fmt.Errorf("invalid session id %q: %w", sid, err)
+		}
+	}
+
+	u, header, err := tailURL(cmd, cfg, userID)
+	if err != nil {
+		return err
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(cmd.Context(), u, header)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return fmt.Errorf("connect %s: %s %s", u, resp.Status, bytes.TrimSpace(body))
+		}
+		return fmt.Errorf("connect %s: %w", u, err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(cmd.ErrOrStderr(), "connected to %s\n", u)
+
+	for i, sid := range sessions {
+		req := map[string]string{"type": "subscribe", "session_id": sid, "request_id": fmt.Sprintf("tail-%d", i+1)}
+		if err := conn.WriteJSON(req); err != nil {
+			return err
+		}
+	}
+
+	// Ctrl+C закрывает подключение close-кадром; ReadMessage тогда возвращает ошибку закрытия.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	go func() {
+		<-sig
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		time.AfterFunc(time.Second, func() { conn.Close() })
+	}()
+
+	compact, _ := f.GetBool("compact")
+	out := cmd.OutOrStdout()
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var ce *websocket.CloseError
+			if errors.As(err, &ce) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "closed: %d %s\n", ce.Code, ce.Text)
+				if ce.Code == websocket.CloseNormalClosure {
+					return nil
+				}
+			}
+			return err
+		}
+		received := time.Now()
+		// Кадр с coalescing (?batch_ms) — JSON-массив сообщений.
+		var msgs []json.RawMessage
+		if d := bytes.TrimSpace(data); len(d) > 0 && d[0] == '[' {
+			if json.Unmarshal(d, &msgs) != nil {
+				msgs = []json.RawMessage{data}
+			}
+		} else {
+			msgs = []json.RawMessage{data}
+		}
+		for _, m := range msgs {
+			printFrame(out, received, m, filters, sel, compact)
+		}
+	}
+}
+
+// tailURL собирает адрес /ws/notify/:user_id с атрибутами клиента и заголовки подключения.
+func tailURL(cmd *cobra.Command, cfg *config.Config, userID uuid.UUID) (string, http.Header, error) {
+	f := cmd.Flags()
+	base, _ := f.GetString("url")
+	if base == "" {
+		base = "ws://localhost:" + cfg.HTTPPort
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", nil, fmt.Errorf("--url: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", nil, fmt.Errorf("--url: unsupported scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/ws/notify/" + userID.String()
+	q := u.Query()
+	if v, _ := f.GetString("region"); v != "" {
+		q.Set("region", v)
+	}
+	if v, _ := f.GetStringSlice("roles"); len(v) > 0 {
+		q.Set("roles", strings.Join(v, ","))
+	}
+	if v, _ := f.GetString("locale"); v != "" {
+		q.Set("locale", v)
+	}
+	u.RawQuery = q.Encode()
+	header := http.Header{}
+	if v, _ := f.GetString("token"); v != "" {
+		header.Set("Authorization", "Bearer "+v)
+	}
+	return u.String(), header, nil
+}
+
+// printFrame печатает сообщение с временем получения, если оно проходит все фильтры.
+func printFrame(w io.Writer, at time.Time, msg json.RawMessage, filters []jsonpath.Condition, sel jsonpath.Path, compact bool) {
+	var v interface{}
+	if err := json.Unmarshal(msg, &v); err != nil {
+		fmt.Fprintf(w, "%s %s\n", at.Format("15:04:05.000"), msg)
+		return
+	}
+	for _, c := range filters {
+		if !c.Match(v) {
+			return
+		}
+	}
+	label := ""
+	if obj, ok := v.(map[string]interface{}); ok {
+		if s, ok := obj["event"].(string); ok {
+			label = s
+		} else if s, ok := obj["type"].(string); ok {
+			label = s
+		}
+	}
+	if sel != nil {
+		var ok bool
+		if v, ok = sel.Get(v); !ok {
+			return
+		}
+	}
+	var body []byte
+	if compact {
+		body, _ = json.Marshal(v)
+	} else {
+		body, _ = json.MarshalIndent(v, "", "  ")
+	}
+	if label != "" {
+		fmt.Fprintf(w, "%s [%s] %s\n", at.Format("15:04:05.000"), label, body)
+	} else {
+		fmt.Fprintf(w, "%s %s\n", at.Format("15:04:05.000"), body)
+	}
+}
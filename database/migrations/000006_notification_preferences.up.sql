@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  user_id UUID NOT NULL,
+  event_type VARCHAR(64) NOT NULL, -- '*' — настройка по умолчанию для всех событий
+  channels TEXT[],                 -- NULL — все каналы
+  muted BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, event_type)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS notification_quiet_hours (
+  user_id UUID PRIMARY KEY,
+  timezone VARCHAR(64) NOT NULL,  -- IANA, например Europe/Moscow
+  start_minute SMALLINT NOT NULL, -- начало окна, минуты от полуночи по местному времени
+  end_minute SMALLINT NOT NULL,   -- конец окна; меньше start_minute — окно через полночь
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
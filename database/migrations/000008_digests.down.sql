@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notification_events_digest_pending;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS digest_status;
+DROP TABLE IF EXISTS notification_digests;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS notification_digests (
+  user_id UUID PRIMARY KEY,
+  interval_seconds INT NOT NULL,
+  channel VARCHAR(32) NOT NULL,       -- websocket, email или push
+  events TEXT[] NOT NULL DEFAULT '{}', -- пусто — события с priority = low
+  next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digests_next_run_at ON notification_digests(next_run_at);
+
+-- digest_status: NULL — обычное событие, pending — ждёт дайджеста, sent — вошло в дайджест,
+-- discarded — дайджест отключён до отправки.
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS digest_status VARCHAR(16);
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_digest_pending
+  ON notification_events(user_id, created_at) WHERE digest_status = 'pending';
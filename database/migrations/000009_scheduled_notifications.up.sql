@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS scheduled_notifications (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  event_type VARCHAR(64) NOT NULL,
+  message JSONB NOT NULL,                         -- конверт события, как в Kafka
+  deliver_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',  -- pending, dispatching, sent, canceled
+  claimed_until TIMESTAMP WITH TIME ZONE,
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_notifications_due
+  ON scheduled_notifications(deliver_at) WHERE status IN ('pending', 'dispatching');
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_templates (
+  event_type VARCHAR(64) NOT NULL,
+  locale VARCHAR(16) NOT NULL, -- BCP 47 в нижнем регистре: ru, en, pt-br
+  title TEXT NOT NULL,
+  body TEXT NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (event_type, locale)
+);
+
+ALTER TABLE notification_contacts ADD COLUMN IF NOT EXISTS locale VARCHAR(16);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notification_events_history_session;
+DROP INDEX IF EXISTS idx_notification_events_history_user;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS notification_id;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS history;
@@ -0,0 +1,10 @@
+-- history: событие, доставленное пользователю маршрутизатором (история уведомлений), в отличие
+-- от записей очереди доставок внешних каналов и дайджестов. notification_id — общий для всех
+-- получателей одного события, его же получает клиент в кадре WebSocket.
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS history BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS notification_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_history_user
+  ON notification_events(user_id, created_at DESC, id DESC) WHERE history;
+CREATE INDEX IF NOT EXISTS idx_notification_events_history_session
+  ON notification_events(session_id, created_at DESC, id DESC) WHERE history;
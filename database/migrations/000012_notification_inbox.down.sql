@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_notification_events_unread;
+DROP INDEX IF EXISTS idx_notification_events_history_notification;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS dismissed_at;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS read_at;
+ALTER TABLE notification_events DROP COLUMN IF EXISTS seen_at;
@@ -0,0 +1,10 @@
+-- Состояние уведомления в истории получателя: просмотрено (seen), прочитано (read), скрыто (dismissed).
+-- Непрочитанные — history без read_at и dismissed_at.
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS seen_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS read_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notification_events ADD COLUMN IF NOT EXISTS dismissed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_history_notification
+  ON notification_events(user_id, notification_id) WHERE history;
+CREATE INDEX IF NOT EXISTS idx_notification_events_unread
+  ON notification_events(user_id, created_at) WHERE history AND read_at IS NULL AND dismissed_at IS NULL;
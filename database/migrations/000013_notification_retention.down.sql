@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_archive;
+DROP INDEX IF EXISTS idx_notification_pending_created_at;
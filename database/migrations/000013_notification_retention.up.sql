@@ -0,0 +1,16 @@
+-- Политика хранения: очистка notification_events и notification_pending идёт пачками по created_at,
+-- удалённые записи можно перенести в notification_archive (строка целиком в data).
+CREATE INDEX IF NOT EXISTS idx_notification_pending_created_at ON notification_pending(created_at);
+
+CREATE TABLE IF NOT EXISTS notification_archive (
+  source VARCHAR(64) NOT NULL,  -- таблица, из которой перенесена запись
+  id UUID NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  user_id UUID,
+  created_at TIMESTAMP WITH TIME ZONE,
+  data JSONB NOT NULL,
+  archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (source, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_archive_created_at ON notification_archive(created_at);
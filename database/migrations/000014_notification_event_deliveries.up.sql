@@ -0,0 +1,4 @@
+-- Одна запись notification_events на получателя события: история и доставки всех внешних каналов
+-- ссылаются на неё по (notification_id, user_id), а не создают свои копии события.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_events_notification_user
+  ON notification_events(notification_id, user_id) WHERE notification_id IS NOT NULL;
@@ -0,0 +1,9 @@
+// Package migrations встраивает SQL-миграции в бинарник (команда migrate и DB_AUTO_MIGRATE).
+package migrations
+
+import "embed"
+
+// FS — файлы NNNNNN_name.up.sql и NNNNNN_name.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
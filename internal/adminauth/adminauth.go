@@ -0,0 +1,21 @@
+// Package adminauth — проверка bearer-токена admin API (ADMIN_TOKEN), общая для gRPC и HTTP.
+package adminauth
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+// Valid проверяет, что среди значений заголовка (metadata) authorization есть «Bearer <token>».
+func Valid(values []string, token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, v := range values {
+		scheme, got, ok := strings.Cut(v, " ")
+		if ok && strings.EqualFold(scheme, "bearer") && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -3,20 +3,38 @@ package application
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/psds-microservice/notification-service/database/migrations"
+	"github.com/psds-microservice/notification-service/internal/channel/email"
+	"github.com/psds-microservice/notification-service/internal/channel/push"
+	"github.com/psds-microservice/notification-service/internal/channel/webhook"
 	"github.com/psds-microservice/notification-service/internal/config"
+	"github.com/psds-microservice/notification-service/internal/delivery"
+	"github.com/psds-microservice/notification-service/internal/digest"
 	grpcserver "github.com/psds-microservice/notification-service/internal/grpc"
 	"github.com/psds-microservice/notification-service/internal/handler"
+	"github.com/psds-microservice/notification-service/internal/health"
+	"github.com/psds-microservice/notification-service/internal/history"
 	"github.com/psds-microservice/notification-service/internal/kafka"
+	"github.com/psds-microservice/notification-service/internal/metrics"
+	"github.com/psds-microservice/notification-service/internal/migrate"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/retention"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/internal/scheduler"
 	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/internal/templates"
+	"github.com/psds-microservice/notification-service/internal/tracing"
 	"github.com/psds-microservice/notification-service/pkg/constants"
 	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -53,11 +71,23 @@ func serveOpenAPISpec() http.HandlerFunc {
 
 // API приложение: HTTP + gRPC серверы (режим api).
 type API struct {
-	cfg     *config.Config
-	httpSrv *http.Server
-	grpcSrv *grpc.Server
-	lis     net.Listener
-	hub     *service.NotifyHub
+	cfg       *config.Config
+	httpSrv   *http.Server
+	grpcSrv   *grpc.Server
+	lis       net.Listener
+	hub       *service.NotifyHub
+	db        *pgxpool.Pool
+	router    *routing.Router
+	worker    *delivery.Worker
+	digests   *digest.Worker
+	scheduler *scheduler.Scheduler
+	purger    *retention.Purger
+	webhook   *webhook.Dispatcher
+	templates *templates.Catalog
+	tracing   func(context.Context) error
+	ready     *health.Readiness
+	kafka     *kafka.Health
+	pending   *repository.PendingRepository
 }
 
 // NewAPI создаёт приложение для режима api.
@@ -66,36 +96,200 @@ func NewAPI(cfg *config.Config) (*API, error) {
 		return nil, fmt.Errorf("config: %w", err)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: constants.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	hub := service.NewNotifyHub(cfg.WSSendQueueSize)
+	metrics.RegisterHub(hub.Stats)
+
+	db, err := repository.NewPool(context.Background(), cfg.DatabaseURL())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DB.AutoMigrate {
+		if err := autoMigrate(db); err != nil {
+			return nil, err
+		}
+	}
+	contacts := repository.NewContactRepository(db)
+	deliveries := repository.NewDeliveryRepository(db)
+	catalog, err := templates.NewCatalog(repository.NewTemplateRepository(db), contacts, cfg.Templates.Dir, cfg.Templates.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	if err := catalog.Reload(context.Background()); err != nil {
+		slog.Error("templates: initial load", "error", err)
+	}
+
+	// Внешние каналы: политика маршрутизации + отправитель для очереди доставок.
+	channelPolicies := make(map[string]routing.ChannelPolicy)
+	senders := make(map[string]delivery.Sender)
+	if cfg.Email.Enabled {
+		policy, err := routing.ParseChannelPolicy(cfg.Email.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("EMAIL_POLICY: %w", err)
+		}
+		emailChannel, err := newEmailChannel(cfg, contacts, catalog)
+		if err != nil {
+			return nil, err
+		}
+		channelPolicies[email.ChannelName] = policy
+		senders[email.ChannelName] = emailChannel
+	}
+	devices := repository.NewDeviceRepository(db)
+	if cfg.Push.Enabled {
+		policy, err := routing.ParseChannelPolicy(cfg.Push.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("PUSH_POLICY: %w", err)
+		}
+		pushChannel, err := newPushChannel(cfg, devices, catalog)
+		if err != nil {
+			return nil, err
+		}
+		channelPolicies[push.ChannelName] = policy
+		senders[push.ChannelName] = pushChannel
+	}
+	webhooks := repository.NewWebhookRepository(db)
+	webhookRegistry := webhook.NewRegistry(webhooks, 0)
+	var webhookPublisher routing.WebhookPublisher
+	var webhookDispatcher *webhook.Dispatcher
+	if cfg.Webhook.Enabled {
+		webhookPublisher = webhookRegistry
+		webhookDispatcher = webhook.NewDispatcher(webhookRegistry, webhooks, webhook.Config{
+			PollInterval:     cfg.Delivery.PollInterval,
+			Concurrency:      cfg.Webhook.Concurrency,
+			Timeout:          cfg.Webhook.Timeout,
+			MaxAttempts:      cfg.Webhook.MaxAttempts,
+			RetryBase:        cfg.Delivery.RetryBase,
+			RetryMax:         cfg.Delivery.RetryMax,
+			BreakerThreshold: cfg.Webhook.BreakerThreshold,
+			BreakerCooldown:  cfg.Webhook.BreakerCooldown,
+		})
+	}
+	preferences := repository.NewPreferenceRepository(db)
+	quietHours := repository.NewQuietHoursRepository(db)
+	digests := repository.NewDigestRepository(db)
+	historyRepo := repository.NewHistoryRepository(db)
+	notificationHistory := history.New(historyRepo, hub)
+	hub.SetHistoryHandler(notificationHistory.HandleWS)
+	hub.SetAckHandler(notificationHistory.HandleAck)
+	hub.SetConnectHandler(notificationHistory.HandleConnect)
+	router := routing.NewRouter(hub, routing.Options{
+		Outbox:      delivery.NewOutbox(deliveries),
+		Channels:    channelPolicies,
+		Webhooks:    webhookPublisher,
+		Preferences: preferences,
+		QuietHours:  quietHours,
+		Digests:     digest.NewCollector(digests),
+		Localizer:   catalog,
+		History:     notificationHistory,
+	})
+	worker := delivery.NewWorker(deliveries, senders, delivery.Config{
+		PollInterval: cfg.Delivery.PollInterval,
+		MaxAttempts:  cfg.Delivery.MaxAttempts,
+		RetryBase:    cfg.Delivery.RetryBase,
+		RetryMax:     cfg.Delivery.RetryMax,
+	})
+	external := make([]string, 0, len(senders))
+	for name := range senders {
+		external = append(external, name)
+	}
+	digestWorker := digest.NewWorker(digests, deliveries, hub, quietHours, external, digest.Config{
+		PollInterval: cfg.Digest.PollInterval,
+		MaxEvents:    cfg.Digest.MaxEvents,
+	})
+	scheduled := repository.NewScheduledRepository(db)
+	var sched *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		sched = scheduler.New(scheduled, router, scheduler.Config{PollInterval: cfg.Scheduler.PollInterval})
+	}
+	var purger *retention.Purger
+	if cfg.Retention.Enabled {
+		if purger, err = NewPurger(cfg, db); err != nil {
+			return nil, err
+		}
+	}
 
 	grpcAddr := cfg.AppHost + ":" + cfg.GRPCPort
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		return nil, fmt.Errorf("grpc listen %s: %w (порт занят — остановите другой процесс или задайте GRPC_PORT в .env)", grpcAddr, err)
 	}
-	grpcSrv := grpc.NewServer()
+	grpcSrv := grpc.NewServer(grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, metrics.UnaryServerInterceptor))
 	grpcImpl := grpcserver.NewServer(grpcserver.Deps{
-		Hub: hub,
+		Hub:         hub,
+		Contacts:    contacts,
+		Devices:     devices,
+		Preferences: preferences,
+		QuietHours:  quietHours,
+		Digests:     digests,
+		Scheduled:   scheduled,
+		Webhooks:    webhookRegistry,
+		Templates:   catalog,
+		History:     notificationHistory,
+		Router:      router,
+		AdminToken:  cfg.AdminToken,
 	})
 	notification_service.RegisterNotificationServiceServer(grpcSrv, grpcImpl)
+	adminImpl := grpcserver.NewAdminServer(hub, cfg.AdminToken)
+	notification_service.RegisterNotificationAdminServiceServer(grpcSrv, adminImpl)
 	reflection.Register(grpcSrv)
 
 	gatewayMux := runtime.NewServeMux()
 	if err := notification_service.RegisterNotificationServiceHandlerServer(context.Background(), gatewayMux, grpcImpl); err != nil {
 		return nil, fmt.Errorf("register grpc-gateway: %w", err)
 	}
+	if err := notification_service.RegisterNotificationAdminServiceHandlerServer(context.Background(), gatewayMux, adminImpl); err != nil {
+		return nil, fmt.Errorf("register grpc-gateway: %w", err)
+	}
 
 	// Gin router для WebSocket
 	gin.SetMode(gin.ReleaseMode)
 	ginRouter := gin.New()
 	ginRouter.Use(gin.Recovery())
-	wsHandler := handler.NewWebSocketHandler(hub)
+	pending := repository.NewPendingRepository(db)
+	wsHandler := handler.NewWebSocketHandler(hub, pending, handler.WebSocketConfig{
+		ReadBufferSize:   cfg.WSReadBufferSize,
+		WriteBufferSize:  cfg.WSWriteBufferSize,
+		Compression:      cfg.WSCompression,
+		CompressionLevel: cfg.WSCompressionLevel,
+		BatchMaxWindow:   cfg.WSBatchMaxWindow,
+		BatchMaxMessages: cfg.WSBatchMaxMessages,
+	})
 	ginRouter.GET("/ws/notify/:user_id", wsHandler.ServeWS)
 
+	// Readiness: Postgres, консьюмер Kafka и Redis (если задан REDIS_URL).
+	kafkaHealth := kafka.NewHealth(cfg.KafkaBrokers, cfg.KafkaTopics)
+	checks := []health.Check{
+		{Name: "postgres", Fn: db.Ping},
+		{Name: "kafka", Fn: kafkaHealth.Check},
+	}
+	if cfg.RedisURL != "" {
+		// Неверный REDIS_URL не мешает запуску: без проверки readiness обходится.
+		if ping, err := health.RedisPing(cfg.RedisURL); err != nil {
+			slog.Warn("health: redis check disabled", "error", err)
+		} else {
+			checks = append(checks, health.Check{Name: "redis", Fn: ping})
+		}
+	}
+	for i := range checks {
+		checks[i].Critical = slices.Contains(cfg.Ready.Critical, checks[i].Name)
+	}
+	readiness := health.NewReadiness(cfg.Ready.CheckTimeout, checks...)
+
 	// Основной HTTP mux: health/ready/swagger через net/http, REST через grpc-gateway, WebSocket через Gin
 	mux := http.NewServeMux()
 	mux.HandleFunc(constants.PathHealth, handler.Health)
-	mux.HandleFunc(constants.PathReady, handler.Ready)
+	mux.Handle(constants.PathReady, readiness)
+	mux.Handle(constants.PathMetrics, metrics.Handler())
+	mux.Handle(constants.PathAdminLogLevel, handler.RequireAdmin(cfg.AdminToken, http.HandlerFunc(handler.LogLevel)))
 	mux.HandleFunc(constants.PathSwagger+"/openapi.json", serveOpenAPISpec())
 	mux.Handle(constants.PathSwagger+"/", httpSwagger.Handler(
 		httpSwagger.URL("openapi.json"),
@@ -104,8 +298,9 @@ func NewAPI(cfg *config.Config) (*API, error) {
 	))
 	// WebSocket через Gin
 	mux.Handle("/ws/", ginRouter)
-	// REST API через grpc-gateway
-	mux.Handle("/", gatewayMux)
+	// REST API через grpc-gateway; все /admin/* — только с ADMIN_TOKEN.
+	mux.Handle("/", tracing.HTTPMiddleware(gatewayMux))
+	mux.Handle("/admin/", handler.RequireAdmin(cfg.AdminToken, tracing.HTTPMiddleware(gatewayMux)))
 
 	httpAddr := cfg.AppHost + ":" + cfg.HTTPPort
 	httpSrv := &http.Server{
@@ -118,14 +313,112 @@ func NewAPI(cfg *config.Config) (*API, error) {
 	}
 
 	return &API{
-		cfg:     cfg,
-		httpSrv: httpSrv,
-		grpcSrv: grpcSrv,
-		lis:     lis,
-		hub:     hub,
+		cfg:       cfg,
+		httpSrv:   httpSrv,
+		grpcSrv:   grpcSrv,
+		lis:       lis,
+		hub:       hub,
+		db:        db,
+		router:    router,
+		worker:    worker,
+		digests:   digestWorker,
+		scheduler: sched,
+		purger:    purger,
+		webhook:   webhookDispatcher,
+		templates: catalog,
+		tracing:   shutdownTracing,
+		ready:     readiness,
+		kafka:     kafkaHealth,
+		pending:   pending,
 	}, nil
 }
 
+// autoMigrate применяет встроенные миграции (DB_AUTO_MIGRATE); реплики ждут друг друга на advisory lock.
+func autoMigrate(db *pgxpool.Pool) error {
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	applied, err := m.Up(context.Background(), 0)
+	if err != nil {
+		return err
+	}
+	slog.Info("migrate: up to date", "applied", len(applied))
+	return nil
+}
+
+// NewPurger собирает очистку по RETENTION_POLICY (фоновая в api и команда purge).
+func NewPurger(cfg *config.Config, db *pgxpool.Pool) (*retention.Purger, error) {
+	policy, err := retention.ParsePolicy(cfg.Retention.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("RETENTION_POLICY: %w", err)
+	}
+	return retention.NewPurger(repository.NewRetentionRepository(db), policy, retention.Config{
+		Interval:  cfg.Retention.Interval,
+		BatchSize: cfg.Retention.BatchSize,
+		Pause:     cfg.Retention.Pause,
+		Archive:   cfg.Retention.Archive,
+	}), nil
+}
+
+// newEmailChannel собирает email-канал: шаблоны, транспорт (SMTP или maildir) и адреса из notification_contacts.
+func newEmailChannel(cfg *config.Config, contacts *repository.ContactRepository, catalog *templates.Catalog) (*email.Channel, error) {
+	renderer, err := email.NewRenderer(cfg.Email.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	var transport email.Transport
+	switch cfg.Email.Transport {
+	case "maildir":
+		if transport, err = email.NewMaildirTransport(cfg.Email.MaildirPath); err != nil {
+			return nil, err
+		}
+	default:
+		transport = email.NewSMTPTransport(email.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+		})
+	}
+	return email.NewChannel(transport, renderer, catalog, contacts, cfg.Email.From), nil
+}
+
+// newPushChannel собирает push-канал: FCM для android/web, APNs для ios (или fake для всех платформ).
+func newPushChannel(cfg *config.Config, devices *repository.DeviceRepository, catalog *templates.Catalog) (*push.Channel, error) {
+	providers := make(map[string]push.Provider)
+	if cfg.Push.Fake {
+		fake := push.NewFakeProvider("fake")
+		for _, platform := range []string{repository.PlatformAndroid, repository.PlatformIOS, repository.PlatformWeb} {
+			providers[platform] = fake
+		}
+		return push.NewChannel(devices, providers, catalog), nil
+	}
+	if cfg.Push.FCMCredentialsFile != "" {
+		fcm, err := push.NewFCMProvider(cfg.Push.FCMCredentialsFile, cfg.Push.FCMProjectID, cfg.Push.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		providers[repository.PlatformAndroid] = fcm
+		providers[repository.PlatformWeb] = fcm
+	}
+	if cfg.Push.APNsKeyFile != "" {
+		apns, err := push.NewAPNsProvider(push.APNsConfig{
+			KeyFile: cfg.Push.APNsKeyFile,
+			KeyID:   cfg.Push.APNsKeyID,
+			TeamID:  cfg.Push.APNsTeamID,
+			Topic:   cfg.Push.APNsTopic,
+			Sandbox: cfg.Push.APNsSandbox,
+			Timeout: cfg.Push.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers[repository.PlatformIOS] = apns
+	}
+	return push.NewChannel(devices, providers, catalog), nil
+}
+
 // Run запускает HTTP и gRPC серверы, блокируется до отмены ctx.
 func (a *API) Run(ctx context.Context) error {
 	httpAddr := a.httpSrv.Addr
@@ -135,36 +428,77 @@ func (a *API) Run(ctx context.Context) error {
 		host = "localhost"
 	}
 	base := "http://" + host + ":" + a.cfg.HTTPPort
-	log.Printf("HTTP server listening on %s", httpAddr)
-	log.Printf("  Swagger UI:    %s/swagger", base)
-	log.Printf("  Swagger spec:  %s/swagger/openapi.json", base)
-	log.Printf("  Health:        %s/health", base)
-	log.Printf("  Ready:         %s/ready", base)
-	log.Printf("  WebSocket:     ws://%s:%s/ws/notify/:user_id", host, a.cfg.HTTPPort)
-	log.Printf("  REST API:      %s/notify/", base)
-	log.Printf("gRPC server listening on %s", grpcAddr)
-	log.Printf("  gRPC endpoint: %s (reflection enabled)", grpcAddr)
-
-	go kafka.RunConsumer(ctx, a.cfg.KafkaBrokers, a.cfg.KafkaGroupID, a.cfg.KafkaTopics, a.hub)
+	slog.Info("HTTP server listening",
+		"addr", httpAddr,
+		"swagger", base+"/swagger",
+		"openapi", base+"/swagger/openapi.json",
+		"health", base+"/health",
+		"ready", base+"/ready",
+		"metrics", base+"/metrics",
+		"websocket", "ws://"+host+":"+a.cfg.HTTPPort+"/ws/notify/:user_id",
+		"rest", base+"/notify/",
+	)
+	slog.Info("gRPC server listening (reflection enabled)", "addr", grpcAddr)
+
+	go kafka.RunConsumer(ctx, a.cfg.KafkaBrokers, a.cfg.KafkaGroupID, a.cfg.KafkaTopics, a.router, a.kafka)
+	go a.worker.Run(ctx)
+	go a.digests.Run(ctx)
+	go a.templates.Run(ctx, a.cfg.Templates.ReloadInterval)
+	if a.scheduler != nil {
+		go a.scheduler.Run(ctx)
+	}
+	if a.webhook != nil {
+		go a.webhook.Run(ctx)
+	}
+	if a.purger != nil {
+		go a.purger.Run(ctx)
+	}
 
 	go func() {
 		if err := a.httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("http: %v", err)
+			slog.Error("http: serve", "error", err)
 		}
 	}()
 
 	go func() {
 		if err := a.grpcSrv.Serve(a.lis); err != nil {
-			log.Printf("grpc: %v", err)
+			slog.Error("grpc: serve", "error", err)
 		}
 	}()
 
 	<-ctx.Done()
+	a.ready.SetDraining()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	// Shutdown не закрывает WebSocket-подключения (они hijacked): их закрывает drainWebSockets.
 	if err := a.httpSrv.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("http shutdown: %w", err)
 	}
 	a.grpcSrv.GracefulStop()
+	a.drainWebSockets()
+	a.db.Close()
+	if err := a.tracing(shutdownCtx); err != nil {
+		slog.Error("tracing: shutdown", "error", err)
+	}
 	return nil
 }
+
+// drainWebSockets дописывает очереди WebSocket-подключений в сокеты (не дольше WS_DRAIN_TIMEOUT),
+// закрывает подключения кадром 1012 и сохраняет неотправленное в notification_pending.
+func (a *API) drainWebSockets() {
+	drainCtx, cancel := context.WithTimeout(context.Background(), a.cfg.WSDrainTimeout)
+	defer cancel()
+	unsent := a.hub.Drain(drainCtx, a.cfg.WSReconnectHint)
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var saved int
+	for userID, frames := range unsent {
+		if err := a.pending.Save(saveCtx, userID, frames); err != nil {
+			slog.Error("ws: drain: save pending", "user_id", userID, "count", len(frames), "error", err)
+			continue
+		}
+		saved += len(frames)
+	}
+	slog.Info("ws: drained", "pending_saved", saved)
+}
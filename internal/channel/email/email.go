@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/delivery"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/templates"
+)
+
+// ChannelName — имя канала в notification_deliveries и политиках маршрутизации.
+const ChannelName = "email"
+
+// Message — письмо, готовое к отправке.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	// ID — идентификатор доставки, используется как Message-ID для идемпотентности на стороне получателя.
+	ID uuid.UUID
+}
+
+// Transport отправляет письмо (SMTP, maildir для локальных запусков и тестов).
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ContactResolver возвращает email пользователя.
+type ContactResolver interface {
+	Email(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// Localizer рендерит заголовок и текст по шаблону уведомления на языке получателя.
+type Localizer interface {
+	RenderEvent(ctx context.Context, ev repository.Event) (templates.Rendered, bool)
+}
+
+// Channel реализует delivery.Sender для email.
+type Channel struct {
+	transport Transport
+	renderer  *Renderer
+	localizer Localizer
+	contacts  ContactResolver
+	from      string
+}
+
+// NewChannel создаёт канал; localizer (может быть nil) важнее email-шаблонов renderer:
+// если для события есть шаблон уведомления, тема и текст берутся из него.
+func NewChannel(transport Transport, renderer *Renderer, localizer Localizer, contacts ContactResolver, from string) *Channel {
+	return &Channel{transport: transport, renderer: renderer, localizer: localizer, contacts: contacts, from: from}
+}
+
+func (c *Channel) Send(ctx context.Context, d repository.Delivery) error {
+	to, err := c.contacts.Email(ctx, d.Event.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return delivery.Permanent(fmt.Errorf("no email for user %s", d.Event.UserID))
+	}
+	if err != nil {
+		return err
+	}
+	var subject, body string
+	if rendered, ok := c.localize(ctx, d.Event); ok {
+		subject, body = rendered.Title, rendered.Body+"\n"
+	} else if subject, body, err = c.renderer.Render(d.Event); err != nil {
+		return delivery.Permanent(err)
+	}
+	return c.transport.Send(ctx, Message{From: c.from, To: to, Subject: subject, Body: body, ID: d.ID})
+}
+
+func (c *Channel) localize(ctx context.Context, ev repository.Event) (templates.Rendered, bool) {
+	if c.localizer == nil {
+		return templates.Rendered{}, false
+	}
+	return c.localizer.RenderEvent(ctx, ev)
+}
+
+// buildRFC822 формирует письмо в формате RFC 5322: заголовки и UTF-8 тело в 8bit.
+func buildRFC822(msg Message) []byte {
+	var b bytes.Buffer
+	host := "localhost"
+	if addr, err := mail.ParseAddress(msg.From); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			host = addr.Address[i+1:]
+		}
+	}
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(msg.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", msg.ID, host)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// headerValue убирает переводы строк, чтобы значение не могло добавить лишние заголовки.
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
@@ -0,0 +1,37 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaildirTransport складывает письма в каталог в формате maildir (tmp/ → new/).
+// Используется для локальных запусков и тестов вместо SMTP.
+type MaildirTransport struct {
+	dir string
+}
+
+func NewMaildirTransport(dir string) (*MaildirTransport, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("maildir: %w", err)
+		}
+	}
+	return &MaildirTransport{dir: dir}, nil
+}
+
+func (t *MaildirTransport) Send(_ context.Context, msg Message) error {
+	host, _ := os.Hostname()
+	name := fmt.Sprintf("%d.%s.%s", time.Now().UnixNano(), msg.ID, host)
+	tmp := filepath.Join(t.dir, "tmp", name)
+	if err := os.WriteFile(tmp, buildRFC822(msg), 0o644); err != nil {
+		return fmt.Errorf("maildir: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(t.dir, "new", name)); err != nil {
+		return fmt.Errorf("maildir: %w", err)
+	}
+	return nil
+}
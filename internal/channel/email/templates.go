@@ -0,0 +1,106 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// defaultTemplateName — шаблон для событий без собственного шаблона.
+const defaultTemplateName = "default"
+
+// TemplateData — данные, доступные в шаблоне письма.
+type TemplateData struct {
+	Event     string
+	UserID    string
+	SessionID string
+	Payload   map[string]interface{}
+	CreatedAt time.Time
+}
+
+// Renderer рендерит тему и текст письма по шаблону события.
+// Шаблон события — файл <event_type>.tmpl с блоками {{define "subject"}} и {{define "body"}}.
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// NewRenderer загружает встроенные шаблоны и, если dir задан, шаблоны из каталога поверх них.
+func NewRenderer(dir string) (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]*template.Template)}
+	if err := r.load(defaultTemplates, "templates"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := r.load(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	if r.templates[defaultTemplateName] == nil {
+		return nil, fmt.Errorf("email: template %q.tmpl is required", defaultTemplateName)
+	}
+	return r, nil
+}
+
+func (r *Renderer) load(fsys fs.FS, root string) error {
+	matches, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(root, "*.tmpl")))
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("email: read template %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		t, err := template.New(name).Option("missingkey=zero").Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("email: parse template %s: %w", path, err)
+		}
+		if t.Lookup("subject") == nil || t.Lookup("body") == nil {
+			return fmt.Errorf("email: template %s must define \"subject\" and \"body\"", path)
+		}
+		r.templates[name] = t
+	}
+	return nil
+}
+
+// Render возвращает тему и текст письма для события.
+func (r *Renderer) Render(ev repository.Event) (subject, body string, err error) {
+	t := r.templates[ev.EventType]
+	if t == nil {
+		t = r.templates[defaultTemplateName]
+	}
+	data := TemplateData{
+		Event:     ev.EventType,
+		UserID:    ev.UserID.String(),
+		CreatedAt: ev.CreatedAt,
+	}
+	if ev.SessionID.Valid {
+		data.SessionID = ev.SessionID.UUID.String()
+	}
+	if len(ev.Payload) > 0 {
+		_ = json.Unmarshal(ev.Payload, &data.Payload)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", fmt.Errorf("email: render subject: %w", err)
+	}
+	subject = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := t.ExecuteTemplate(&buf, "body", data); err != nil {
+		return "", "", fmt.Errorf("email: render body: %w", err)
+	}
+	return subject, strings.TrimSpace(buf.String()) + "\n", nil
+}
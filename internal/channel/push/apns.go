@@ -0,0 +1,150 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/delivery"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	// apnsTokenTTL — APNs принимает provider token не старше часа; обновляем заранее.
+	apnsTokenTTL = 50 * time.Minute
+)
+
+// APNsConfig — параметры token-based аутентификации APNs (ключ .p8).
+type APNsConfig struct {
+	KeyFile string
+	KeyID   string
+	TeamID  string
+	Topic   string // bundle id приложения
+	Sandbox bool
+	Timeout time.Duration
+}
+
+// APNsProvider отправляет push через Apple Push Notification service (HTTP/2).
+type APNsProvider struct {
+	cfg    APNsConfig
+	key    crypto.Signer
+	host   string
+	client *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+func NewAPNsProvider(cfg APNsConfig) (*APNsProvider, error) {
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("apns: %w", err)
+	}
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("apns: private key: %w", err)
+	}
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, fmt.Errorf("apns: key id, team id and topic are required")
+	}
+	host := apnsProductionHost
+	if cfg.Sandbox {
+		host = apnsSandboxHost
+	}
+	return &APNsProvider{cfg: cfg, key: key, host: host, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+func (p *APNsProvider) Send(ctx context.Context, n Notification) error {
+	token, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": n.Title, "body": n.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range n.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+n.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("apns: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	_ = json.Unmarshal(respBody, &apnsErr)
+	if apnsErr.Reason == "ExpiredProviderToken" {
+		p.mu.Lock()
+		p.jwt = ""
+		p.mu.Unlock()
+	}
+	return apnsError(resp.StatusCode, apnsErr.Reason, respBody)
+}
+
+// apnsError разбирает ответ APNs с ошибкой. Недействителен токен только при 410, BadDeviceToken
+// и Unregistered; ошибки темы (DeviceTokenNotForTopic, TopicDisallowed, BadTopic) означают неверный
+// APNS_TOPIC — они окончательные, но токены не удаляют.
+func apnsError(status int, reason string, body []byte) error {
+	err := fmt.Errorf("apns: status %d: %s", status, strings.TrimSpace(string(body)))
+	switch reason {
+	case "BadDeviceToken", "Unregistered":
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	case "DeviceTokenNotForTopic", "TopicDisallowed", "BadTopic", "MissingTopic":
+		return delivery.Permanent(err)
+	}
+	if status == http.StatusGone {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return err
+}
+
+// providerToken возвращает JWT (ES256) для заголовка authorization, перевыпуская его раз в apnsTokenTTL.
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.jwt != "" && time.Since(p.issuedAt) < apnsTokenTTL {
+		return p.jwt, nil
+	}
+	now := time.Now()
+	token, err := signJWT(
+		map[string]interface{}{"alg": "ES256", "kid": p.cfg.KeyID},
+		map[string]interface{}{"iss": p.cfg.TeamID, "iat": now.Unix()},
+		p.key)
+	if err != nil {
+		return "", fmt.Errorf("apns: sign token: %w", err)
+	}
+	p.jwt, p.issuedAt = token, now
+	return token, nil
+}
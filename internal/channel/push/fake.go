@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// fakeSentLimit — сколько последних отправленных уведомлений хранит FakeProvider.
+const fakeSentLimit = 100
+
+// FakeProvider запоминает последние отправленные уведомления (не больше fakeSentLimit) вместо
+// реальной отправки — для локальных запусков и тестов. Токены из Invalid считаются недействительными.
+type FakeProvider struct {
+	Name    string
+	Invalid map[string]bool
+
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func NewFakeProvider(name string) *FakeProvider {
+	return &FakeProvider{Name: name, Invalid: make(map[string]bool)}
+}
+
+func (p *FakeProvider) Send(ctx context.Context, n Notification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Invalid[n.Token] {
+		return ErrInvalidToken
+	}
+	if len(p.sent) >= fakeSentLimit {
+		p.sent = append(p.sent[:0], p.sent[len(p.sent)-fakeSentLimit+1:]...)
+	}
+	p.sent = append(p.sent, n)
+	slog.DebugContext(ctx, "push: fake send", "provider", p.Name, "token", shortToken(n.Token), "title", n.Title)
+	return nil
+}
+
+// Sent возвращает копию последних отправленных уведомлений.
+func (p *FakeProvider) Sent() []Notification {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Notification(nil), p.sent...)
+}
+
+// shortToken — начало токена устройства для логов: полный токен позволяет отправлять push на устройство.
+func shortToken(token string) string {
+	if len(token) <= 8 {
+		return "…"
+	}
+	return token[:8] + "…"
+}
@@ -0,0 +1,191 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/delivery"
+)
+
+const (
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// serviceAccount — нужные поля JSON-ключа сервисного аккаунта Google.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider отправляет push через Firebase Cloud Messaging HTTP v1 API.
+// Access token получается по JWT сервисного аккаунта и кэшируется до истечения.
+type FCMProvider struct {
+	projectID string
+	account   serviceAccount
+	key       crypto.Signer
+	client    *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider читает ключ сервисного аккаунта; projectID переопределяет project_id из ключа.
+func NewFCMProvider(credentialsFile, projectID string, timeout time.Duration) (*FCMProvider, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("fcm: %w", err)
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("fcm: parse credentials: %w", err)
+	}
+	key, err := parsePrivateKey([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm: private key: %w", err)
+	}
+	if projectID == "" {
+		projectID = sa.ProjectID
+	}
+	if projectID == "" || sa.ClientEmail == "" || sa.TokenURI == "" {
+		return nil, errors.New("fcm: credentials must contain project_id, client_email and token_uri")
+	}
+	return &FCMProvider{projectID: projectID, account: sa, key: key, client: &http.Client{Timeout: timeout}}, nil
+}
+
+func (p *FCMProvider) Send(ctx context.Context, n Notification) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        n.Token,
+			"notification": map[string]string{"title": n.Title, "body": n.Body},
+			"data":         n.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmEndpoint, p.projectID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusUnauthorized {
+		p.mu.Lock()
+		p.accessToken = ""
+		p.mu.Unlock()
+	}
+	return fcmError(resp.StatusCode, respBody)
+}
+
+// fcmError разбирает ответ FCM с ошибкой. Недействительным считается только токен, о котором это сказано
+// явно (UNREGISTERED или INVALID_ARGUMENT по полю message.token): 404 без UNREGISTERED означает
+// неверный project_id, и удалять из-за него токены всех получателей нельзя — такая ошибка окончательная.
+func fcmError(status int, body []byte) error {
+	var resp struct {
+		Error struct {
+			Details []struct {
+				ErrorCode       string `json:"errorCode"`
+				FieldViolations []struct {
+					Field string `json:"field"`
+				} `json:"fieldViolations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &resp)
+	var code string
+	tokenField := false
+	for _, d := range resp.Error.Details {
+		if d.ErrorCode != "" {
+			code = d.ErrorCode
+		}
+		for _, v := range d.FieldViolations {
+			tokenField = tokenField || v.Field == "message.token"
+		}
+	}
+	err := fmt.Errorf("fcm: status %d: %s", status, strings.TrimSpace(string(body)))
+	switch {
+	case code == "UNREGISTERED", code == "INVALID_ARGUMENT" && tokenField:
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	case status == http.StatusNotFound, code == "SENDER_ID_MISMATCH", code == "THIRD_PARTY_AUTH_ERROR":
+		return delivery.Permanent(err)
+	}
+	return err
+}
+
+// token возвращает OAuth2 access token, обменивая подписанный JWT на token_uri (grant jwt-bearer).
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.accessToken != "" && time.Until(p.expiresAt) > time.Minute {
+		return p.accessToken, nil
+	}
+	now := time.Now()
+	assertion, err := signJWT(
+		map[string]interface{}{"alg": "RS256", "typ": "JWT"},
+		map[string]interface{}{
+			"iss":   p.account.ClientEmail,
+			"scope": fcmScope,
+			"aud":   p.account.TokenURI,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		}, p.key)
+	if err != nil {
+		return "", fmt.Errorf("fcm: sign assertion: %w", err)
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm: token: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("fcm: token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("fcm: token: %w", err)
+	}
+	p.accessToken = tok.AccessToken
+	p.expiresAt = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
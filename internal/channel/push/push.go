@@ -0,0 +1,121 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/delivery"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/templates"
+)
+
+// ChannelName — имя канала в notification_deliveries и политиках маршрутизации.
+const ChannelName = "push"
+
+// ErrInvalidToken — провайдер сообщил, что токен устройства больше недействителен.
+var ErrInvalidToken = errors.New("push: invalid device token")
+
+// Notification — push-уведомление для одного устройства.
+type Notification struct {
+	Token string
+	Title string
+	Body  string
+	// Data — произвольные данные для приложения (event, session_id и т.п.).
+	Data map[string]string
+}
+
+// Provider отправляет push через конкретный сервис (FCM, APNs).
+type Provider interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// DeviceStore — реестр push-токенов.
+type DeviceStore interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]repository.Device, error)
+	DeleteToken(ctx context.Context, token string) error
+}
+
+// Localizer рендерит заголовок и текст по шаблону уведомления на языке получателя.
+type Localizer interface {
+	RenderEvent(ctx context.Context, ev repository.Event) (templates.Rendered, bool)
+}
+
+// Channel реализует delivery.Sender для push: отправляет уведомление на все устройства пользователя
+// через провайдера их платформы.
+type Channel struct {
+	devices   DeviceStore
+	providers map[string]Provider
+	localizer Localizer
+}
+
+// NewChannel принимает провайдеров по платформам (repository.PlatformAndroid и т.д.);
+// localizer (может быть nil) задаёт заголовок и текст по шаблону уведомления.
+func NewChannel(devices DeviceStore, providers map[string]Provider, localizer Localizer) *Channel {
+	return &Channel{devices: devices, providers: providers, localizer: localizer}
+}
+
+// Send считает доставку успешной, если уведомление ушло хотя бы на одно устройство:
+// повтор ради остальных продублировал бы push на уже получивших его устройствах.
+func (c *Channel) Send(ctx context.Context, d repository.Delivery) error {
+	devices, err := c.devices.ListByUser(ctx, d.Event.UserID)
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return delivery.Permanent(fmt.Errorf("no devices for user %s", d.Event.UserID))
+	}
+	n := buildNotification(d.Event)
+	if c.localizer != nil {
+		if rendered, ok := c.localizer.RenderEvent(ctx, d.Event); ok {
+			n.Title, n.Body = rendered.Title, rendered.Body
+		}
+	}
+	var lastErr error
+	sent := 0
+	for _, dev := range devices {
+		provider := c.providers[dev.Platform]
+		if provider == nil {
+			lastErr = fmt.Errorf("no push provider for platform %q", dev.Platform)
+			continue
+		}
+		n.Token = dev.Token
+		err := provider.Send(ctx, n)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, ErrInvalidToken):
+			if err := c.devices.DeleteToken(ctx, dev.Token); err != nil {
+				slog.ErrorContext(ctx, "push: delete invalid token", "user_id", d.Event.UserID, "error", err)
+			}
+			lastErr = err
+		default:
+			lastErr = err
+		}
+	}
+	if sent > 0 {
+		return nil
+	}
+	return lastErr
+}
+
+// buildNotification берёт title/body из payload события, иначе — имя события.
+func buildNotification(ev repository.Event) Notification {
+	n := Notification{Title: ev.EventType, Data: map[string]string{"event": ev.EventType}}
+	if ev.SessionID.Valid {
+		n.Data["session_id"] = ev.SessionID.UUID.String()
+	}
+	var payload map[string]interface{}
+	if len(ev.Payload) > 0 && json.Unmarshal(ev.Payload, &payload) == nil {
+		if v, ok := payload["title"].(string); ok && v != "" {
+			n.Title = v
+		}
+		if v, ok := payload["body"].(string); ok {
+			n.Body = v
+		}
+	}
+	return n
+}
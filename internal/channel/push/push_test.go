@@ -0,0 +1,123 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/delivery"
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// errorKind — как Channel.Send обходится с ошибкой провайдера.
+type errorKind int
+
+const (
+	retryable errorKind = iota
+	permanent
+	invalidToken
+)
+
+func kindOf(err error) errorKind {
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		return invalidToken
+	case delivery.IsPermanent(err):
+		return permanent
+	}
+	return retryable
+}
+
+func TestFCMError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   errorKind
+	}{
+		{"unregistered", http.StatusNotFound, `{"error":{"code":404,"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`, invalidToken},
+		{"invalid token field", http.StatusBadRequest, `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.token","description":"Invalid registration token"}]}]}}`, invalidToken},
+		{"invalid other field", http.StatusBadRequest, `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.data"}]}]}}`, retryable},
+		{"wrong project", http.StatusNotFound, `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND"}}`, permanent},
+		{"sender id mismatch", http.StatusForbidden, `{"error":{"code":403,"details":[{"errorCode":"SENDER_ID_MISMATCH"}]}}`, permanent},
+		{"quota", http.StatusTooManyRequests, `{"error":{"code":429,"details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`, retryable},
+		{"unavailable", http.StatusServiceUnavailable, `upstream error`, retryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kindOf(fcmError(tt.status, []byte(tt.body))); got != tt.want {
+				t.Errorf("fcmError() kind = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPNsError(t *testing.T) {
+	tests := []struct {
+		status int
+		reason string
+		want   errorKind
+	}{
+		{http.StatusGone, "Unregistered", invalidToken},
+		{http.StatusGone, "", invalidToken},
+		{http.StatusBadRequest, "BadDeviceToken", invalidToken},
+		{http.StatusBadRequest, "DeviceTokenNotForTopic", permanent},
+		{http.StatusBadRequest, "TopicDisallowed", permanent},
+		{http.StatusBadRequest, "BadTopic", permanent},
+		{http.StatusForbidden, "ExpiredProviderToken", retryable},
+		{http.StatusTooManyRequests, "TooManyRequests", retryable},
+		{http.StatusServiceUnavailable, "ServiceUnavailable", retryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			if got := kindOf(apnsError(tt.status, tt.reason, nil)); got != tt.want {
+				t.Errorf("apnsError(%d, %s) kind = %d, want %d", tt.status, tt.reason, got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeDevices struct {
+	devices []repository.Device
+	deleted []string
+}
+
+func (f *fakeDevices) ListByUser(context.Context, uuid.UUID) ([]repository.Device, error) {
+	return f.devices, nil
+}
+
+func (f *fakeDevices) DeleteToken(_ context.Context, token string) error {
+	f.deleted = append(f.deleted, token)
+	return nil
+}
+
+type providerFunc func(n Notification) error
+
+func (f providerFunc) Send(_ context.Context, n Notification) error { return f(n) }
+
+func TestChannelSendDeletesOnlyInvalidTokens(t *testing.T) {
+	devices := &fakeDevices{devices: []repository.Device{
+		{Platform: repository.PlatformAndroid, Token: "unregistered"},
+		{Platform: repository.PlatformAndroid, Token: "wrong-project"},
+		{Platform: repository.PlatformAndroid, Token: "unavailable"},
+	}}
+	provider := providerFunc(func(n Notification) error {
+		switch n.Token {
+		case "unregistered":
+			return fcmError(http.StatusNotFound, []byte(`{"error":{"details":[{"errorCode":"UNREGISTERED"}]}}`))
+		case "wrong-project":
+			return fcmError(http.StatusNotFound, []byte(`{"error":{"status":"NOT_FOUND"}}`))
+		}
+		return fcmError(http.StatusServiceUnavailable, nil)
+	})
+	c := NewChannel(devices, map[string]Provider{repository.PlatformAndroid: provider}, nil)
+	err := c.Send(context.Background(), repository.Delivery{Event: repository.Event{UserID: uuid.New(), EventType: "session.created"}})
+	if err == nil {
+		t.Fatal("Send() succeeded, want error")
+	}
+	if len(devices.deleted) != 1 || devices.deleted[0] != "unregistered" {
+		t.Errorf("deleted tokens = %v, want [unregistered]", devices.deleted)
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress — адрес webhook указывает во внутреннюю сеть сервиса.
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// publicAddr сообщает, можно ли отправлять webhook на адрес: loopback, частные, link-local,
+// multicast и неуказанные адреса запрещены, чтобы подписка не открывала доступ к внутренним хостам.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// CheckHost проверяет хост URL подписки: IP-адрес и все адреса, в которые резолвится имя,
+// должны быть публичными. Ошибка резолвинга не мешает регистрации — адрес ещё раз проверяется при отправке.
+func CheckHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddr(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range addrs {
+		if !publicAddr(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, ip)
+		}
+	}
+	return nil
+}
+
+// newDialer возвращает dialer, который отказывается подключаться к непубличным адресам.
+// Проверка идёт после резолвинга, поэтому её не обойти DNS-записью, сменившейся после регистрации, или редиректом.
+func newDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(ap.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, ap.Addr())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"203.0.113.10", true},
+		{"2001:db8::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"localhost", false},
+		{"api.localhost", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := CheckHost(context.Background(), tt.host)
+			if tt.allowed && err != nil {
+				t.Errorf("CheckHost() = %v, want nil", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbiddenAddress) {
+				t.Errorf("CheckHost() = %v, want %v", err, ErrForbiddenAddress)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker — circuit breaker по endpoint (URL): после threshold неудач подряд
+// запросы к endpoint не отправляются cooldown; первая попытка после паузы пробная,
+// её неудача снова размыкает цепь.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointState
+}
+
+type endpointState struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	return &breaker{threshold: threshold, cooldown: cooldown, endpoints: make(map[string]*endpointState)}
+}
+
+// Allow сообщает, можно ли отправлять запрос; если нет — до какого момента цепь разомкнута.
+func (b *breaker) Allow(endpoint string) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.endpoints[endpoint]
+	if st == nil || !time.Now().Before(st.openUntil) {
+		return true, time.Time{}
+	}
+	return false, st.openUntil
+}
+
+func (b *breaker) Success(endpoint string) {
+	b.mu.Lock()
+	delete(b.endpoints, endpoint)
+	b.mu.Unlock()
+}
+
+func (b *breaker) Failure(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.endpoints[endpoint]
+	if st == nil {
+		st = &endpointState{}
+		b.endpoints[endpoint] = st
+	}
+	st.failures++
+	if st.failures >= b.threshold {
+		st.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/delivery"
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// Config — параметры отправки webhook.
+type Config struct {
+	PollInterval     time.Duration
+	BatchSize        int
+	Concurrency      int
+	Timeout          time.Duration
+	MaxAttempts      int
+	RetryBase        time.Duration
+	RetryMax         time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Dispatcher разбирает webhook_deliveries и отправляет подписанные POST-запросы партнёрам.
+type Dispatcher struct {
+	registry *Registry
+	repo     *repository.WebhookRepository
+	client   *http.Client
+	breaker  *breaker
+	cfg      Config
+}
+
+func NewDispatcher(registry *Registry, repo *repository.WebhookRepository, cfg Config) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 8
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	return &Dispatcher{
+		registry: registry,
+		repo:     repo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// Без прокси: адрес партнёра проверяет dialer, а не прокси.
+			Transport: &http.Transport{
+				DialContext:         newDialer(cfg.Timeout).DialContext,
+				TLSHandshakeTimeout: cfg.Timeout,
+				MaxIdleConnsPerHost: cfg.Concurrency,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		cfg:     cfg,
+	}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			batch, err := d.repo.ClaimDue(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "webhook: claim", "error", err)
+				}
+				break
+			}
+			d.processBatch(ctx, batch)
+			if len(batch) < d.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) processBatch(ctx context.Context, batch []repository.WebhookDelivery) {
+	sem := make(chan struct{}, d.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, del := range batch {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(del repository.WebhookDelivery) {
+			defer func() { <-sem; wg.Done() }()
+			d.process(ctx, del)
+		}(del)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) process(ctx context.Context, del repository.WebhookDelivery) {
+	sub, err := d.registry.subscription(ctx, del.SubscriptionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhook: subscription", "subscription_id", del.SubscriptionID, "error", err)
+		return // lease истечёт, доставка вернётся в очередь
+	}
+	if sub == nil {
+		d.logUpdate(d.repo.MarkFailed(ctx, del.ID, 0, "subscription is inactive"), del)
+		return
+	}
+	if ok, until := d.breaker.Allow(sub.URL); !ok {
+		d.logUpdate(d.repo.Defer(ctx, del.ID, until), del)
+		return
+	}
+
+	code, err := d.post(ctx, sub, del)
+	if err == nil {
+		d.breaker.Success(sub.URL)
+		d.logUpdate(d.repo.MarkDelivered(ctx, del.ID, code), del)
+		return
+	}
+	// Окончательные ошибки (4xx) означают, что endpoint отвечает, — цепь из-за них не размыкается.
+	var perm permanentError
+	permanent := errors.As(err, &perm)
+	if !permanent {
+		d.breaker.Failure(sub.URL)
+	}
+	if permanent || del.Attempts >= d.cfg.MaxAttempts {
+		slog.WarnContext(ctx, "webhook: delivery failed", "delivery_id", del.ID, "url", sub.URL, "attempts", del.Attempts, "error", err)
+		d.logUpdate(d.repo.MarkFailed(ctx, del.ID, code, err.Error()), del)
+		return
+	}
+	next := time.Now().Add(delivery.Backoff(d.cfg.RetryBase, d.cfg.RetryMax, del.Attempts))
+	d.logUpdate(d.repo.MarkRetry(ctx, del.ID, code, err.Error(), next), del)
+}
+
+type permanentError struct{ error }
+
+// post отправляет подписанный запрос и возвращает HTTP-статус (0, если ответа не было).
+// 2xx — успех; 4xx, кроме 408 и 429, и непубличный адрес — окончательная ошибка; остальное повторяется.
+func (d *Dispatcher) post(ctx context.Context, sub *repository.Webhook, del repository.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "psds-notification-service")
+	req.Header.Set(HeaderEvent, del.EventType)
+	req.Header.Set(HeaderDelivery, del.ID.String())
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, time.Now().Unix(), del.Payload))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, ErrForbiddenAddress) {
+		return 0, permanentError{err}
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return resp.StatusCode, permanentError{err}
+	}
+	return resp.StatusCode, err
+}
+
+func (d *Dispatcher) logUpdate(err error, del repository.WebhookDelivery) {
+	if err != nil {
+		slog.Error("webhook: update delivery", "delivery_id", del.ID, "error", err)
+	}
+}
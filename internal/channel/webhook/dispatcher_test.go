@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+func TestDispatcherPost(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantErr   bool
+		permanent bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNoContent, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusGone, true, true},
+		{http.StatusRequestTimeout, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusServiceUnavailable, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var signature string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				signature = r.Header.Get(HeaderSignature)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			d := NewDispatcher(nil, nil, Config{})
+			d.client = srv.Client() // httptest слушает loopback, который dialer диспетчера запрещает
+			sub := &repository.Webhook{URL: srv.URL, Secret: "secret"}
+			del := repository.WebhookDelivery{ID: uuid.New(), EventType: "session.created", Payload: []byte(`{}`)}
+
+			code, err := d.post(context.Background(), sub, del)
+			if code != tt.status {
+				t.Errorf("status = %d, want %d", code, tt.status)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var perm permanentError
+			if errors.As(err, &perm) != tt.permanent {
+				t.Errorf("permanent = %v, want %v", !tt.permanent, tt.permanent)
+			}
+			if signature == "" {
+				t.Error("request is not signed")
+			}
+		})
+	}
+}
+
+func TestDispatcherPostForbiddenAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback address")
+	}))
+	defer srv.Close()
+	d := NewDispatcher(nil, nil, Config{})
+	sub := &repository.Webhook{URL: srv.URL, Secret: "secret"}
+	del := repository.WebhookDelivery{ID: uuid.New(), EventType: "session.created", Payload: []byte(`{}`)}
+
+	_, err := d.post(context.Background(), sub, del)
+	var perm permanentError
+	if !errors.As(err, &perm) || !errors.Is(perm.error, ErrForbiddenAddress) {
+		t.Errorf("err = %v, want permanent %v", err, ErrForbiddenAddress)
+	}
+}
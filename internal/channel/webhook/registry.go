@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/routing"
+)
+
+// Registry — реестр webhook-подписок: CRUD поверх репозитория и кэш активных подписок
+// для сопоставления событий. Кэш сбрасывается при изменениях через API и раз в refresh.
+type Registry struct {
+	repo    *repository.WebhookRepository
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	active   []repository.Webhook
+	loadedAt time.Time
+}
+
+func NewRegistry(repo *repository.WebhookRepository, refresh time.Duration) *Registry {
+	if refresh <= 0 {
+		refresh = 30 * time.Second
+	}
+	return &Registry{repo: repo, refresh: refresh}
+}
+
+// GenerateSecret возвращает случайный секрет для подписи запросов.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *Registry) Create(ctx context.Context, w repository.Webhook) (repository.Webhook, error) {
+	created, err := r.repo.Create(ctx, w)
+	r.invalidate()
+	return created, err
+}
+
+func (r *Registry) Get(ctx context.Context, id uuid.UUID) (repository.Webhook, error) {
+	return r.repo.Get(ctx, id)
+}
+
+func (r *Registry) List(ctx context.Context) ([]repository.Webhook, error) {
+	return r.repo.List(ctx, false)
+}
+
+func (r *Registry) Update(ctx context.Context, w repository.Webhook, active *bool) (repository.Webhook, error) {
+	updated, err := r.repo.Update(ctx, w, active)
+	r.invalidate()
+	return updated, err
+}
+
+func (r *Registry) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.repo.Delete(ctx, id)
+	r.invalidate()
+	return err
+}
+
+func (r *Registry) ListDeliveries(ctx context.Context, id uuid.UUID, after *repository.Cursor, limit int) ([]repository.WebhookDelivery, error) {
+	return r.repo.ListDeliveries(ctx, id, after, limit)
+}
+
+func (r *Registry) invalidate() {
+	r.mu.Lock()
+	r.loadedAt = time.Time{}
+	r.mu.Unlock()
+}
+
+// activeSubscriptions возвращает активные подписки из кэша, перечитывая их при устаревании.
+func (r *Registry) activeSubscriptions(ctx context.Context) ([]repository.Webhook, error) {
+	r.mu.RLock()
+	if time.Since(r.loadedAt) < r.refresh {
+		subs := r.active
+		r.mu.RUnlock()
+		return subs, nil
+	}
+	r.mu.RUnlock()
+
+	subs, err := r.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.active, r.loadedAt = subs, time.Now()
+	r.mu.Unlock()
+	return subs, nil
+}
+
+// subscription возвращает активную подписку по id из кэша (nil, если её нет или она отключена).
+func (r *Registry) subscription(ctx context.Context, id uuid.UUID) (*repository.Webhook, error) {
+	subs, err := r.activeSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if subs[i].ID == id {
+			return &subs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Matches сообщает, подходит ли событие под фильтры подписки:
+// пустой список — все события, "prefix.*" — по префиксу, иначе точное совпадение.
+func Matches(filters []string, event string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f == "*" || f == event {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(f, "*"); ok && strings.HasPrefix(event, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload — тело POST-запроса к партнёру.
+type Payload struct {
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Publish реализует routing.WebhookPublisher: ставит событие в очередь для подходящих подписок.
+func (r *Registry) Publish(ctx context.Context, msg routing.Message, raw []byte) error {
+	if msg.Event == "" {
+		return nil
+	}
+	subs, err := r.activeSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("webhook: load subscriptions: %w", err)
+	}
+	var ids []uuid.UUID
+	for _, s := range subs {
+		if Matches(s.Events, msg.Event) {
+			ids = append(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(Payload{Event: msg.Event, OccurredAt: time.Now().UTC(), Data: raw})
+	if err != nil {
+		return err
+	}
+	return r.repo.EnqueueDeliveries(ctx, ids, msg.Event, body)
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Заголовки запроса к партнёру.
+const (
+	HeaderSignature = "X-PSDS-Signature"
+	HeaderEvent     = "X-PSDS-Event"
+	HeaderDelivery  = "X-PSDS-Delivery"
+)
+
+// Sign возвращает значение заголовка X-PSDS-Signature: "t=<unix>,v1=<hex>",
+// где v1 = HMAC-SHA256(secret, "<unix>.<body>"). Метка времени в подписи защищает от повторов.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"session.created"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      string
+	}{
+		{"payload", "secret", 1700000000, body, "t=1700000000,v1=aaa3e22bf5930181c35b7beb903bc3c1f71181c1e33c7553fc418dd5eb0780c3"},
+		{"other secret", "other", 1700000000, body, "t=1700000000,v1=45dd2f0eca4c41033a3297e1ff74c2dac56ca565d666b196d74a158e3b044052"},
+		{"other timestamp", "secret", 1700000001, body, "t=1700000001,v1=1f084cc4748767c927a1d7e7c5fc5871910888b6795335c2ee8e91dfe36a70f1"},
+		{"empty body", "secret", 0, nil, "t=0,v1=3445798a051818ef95def46c2eb62b43d377ce6e3c29b4d0aec3da0e59577f79"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"compress/flate"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +19,8 @@ type Config struct {
 	GRPCPort string
 	AppEnv   string
 	LogLevel string
+	// LogFormat — json или text.
+	LogFormat string
 
 	KafkaBrokers []string
 	KafkaGroupID string
@@ -29,11 +33,122 @@ type Config struct {
 		Password string
 		Database string
 		SSLMode  string
+		// AutoMigrate — применять встроенные миграции при старте api.
+		AutoMigrate bool
 	}
 
 	WSReadBufferSize  int
 	WSWriteBufferSize int
 	WSSendQueueSize   int
+
+	// permessage-deflate: согласуется, только если включено и клиент его предлагает.
+	WSCompression      bool
+	WSCompressionLevel int
+	// Верхние границы для per-connection coalescing (?batch_ms=...).
+	WSBatchMaxWindow   time.Duration
+	WSBatchMaxMessages int
+	// Остановка: сколько ждать, пока очереди подключений допишутся в сокеты, и верхняя граница
+	// подсказки reconnect_after_ms в close-кадре 1012.
+	WSDrainTimeout  time.Duration
+	WSReconnectHint time.Duration
+
+	// Email-канал: политика "event:mode" (never/offline/always), транспорт smtp или maildir.
+	Email struct {
+		Enabled      bool
+		Policy       string
+		Transport    string
+		From         string
+		TemplatesDir string
+		MaildirPath  string
+	}
+	SMTP struct {
+		Host     string
+		Port     string
+		Username string
+		Password string
+	}
+
+	// Push-канал: FCM (android, web) и APNs (ios); Fake — вместо реальных провайдеров.
+	Push struct {
+		Enabled            bool
+		Policy             string
+		Fake               bool
+		Timeout            time.Duration
+		FCMCredentialsFile string
+		FCMProjectID       string
+		APNsKeyFile        string
+		APNsKeyID          string
+		APNsTeamID         string
+		APNsTopic          string
+		APNsSandbox        bool
+	}
+
+	// Webhook-канал: доставка событий партнёрам по подпискам из webhook_subscriptions.
+	Webhook struct {
+		Enabled          bool
+		Timeout          time.Duration
+		MaxAttempts      int
+		Concurrency      int
+		BreakerThreshold int
+		BreakerCooldown  time.Duration
+	}
+
+	// Дайджесты: как часто проверять наступившие и сколько событий максимум в одном дайджесте.
+	Digest struct {
+		PollInterval time.Duration
+		MaxEvents    int
+	}
+
+	// Отложенные уведомления: цикл отправки в процессе api.
+	Scheduler struct {
+		Enabled      bool
+		PollInterval time.Duration
+	}
+
+	// Политика хранения notification_events и notification_pending: "event:ttl" (30d, 720h, keep).
+	// Enabled — фоновая очистка в процессе api; вручную — команда purge.
+	Retention struct {
+		Enabled   bool
+		Policy    string
+		Interval  time.Duration
+		BatchSize int
+		Pause     time.Duration
+		Archive   bool
+	}
+
+	// Шаблоны уведомлений: файлы <event_type>.<locale>.tmpl и notification_templates.
+	Templates struct {
+		Dir            string
+		DefaultLocale  string
+		ReloadInterval time.Duration
+	}
+
+	// RedisURL — если задан, Redis проверяется в /ready.
+	RedisURL string
+
+	// AdminToken — bearer-токен admin API (NotificationAdminService); пустой — admin API выключен.
+	AdminToken string
+
+	// Readiness: таймаут проверки зависимости и зависимости, без которых под не готов.
+	Ready struct {
+		CheckTimeout time.Duration
+		Critical     []string
+	}
+
+	// Трассировка OpenTelemetry: экспортёр none, otlp, stdout или file.
+	Tracing struct {
+		Exporter    string
+		File        string
+		SampleRatio float64
+	}
+
+	// Очередь доставок во внешние каналы (notification_deliveries).
+	Delivery struct {
+		PollInterval time.Duration
+		MaxAttempts  int
+		RetryBase    time.Duration
+		RetryMax     time.Duration
+	}
 }
 
 func Load() (*Config, error) {
@@ -60,6 +175,15 @@ func Load() (*Config, error) {
 	if sendQueue <= 0 {
 		sendQueue = 256
 	}
+	compressionLevel, _ := strconv.Atoi(getEnv("WS_COMPRESSION_LEVEL", "1"))
+	batchMaxWindow, err := getEnvDuration("WS_BATCH_MAX_WINDOW", "500ms")
+	if err != nil {
+		return nil, err
+	}
+	batchMaxMessages, _ := strconv.Atoi(getEnv("WS_BATCH_MAX_MESSAGES", "100"))
+	if batchMaxMessages <= 0 {
+		batchMaxMessages = 100
+	}
 
 	cfg := &Config{
 		AppHost:           getEnv("APP_HOST", "0.0.0.0"),
@@ -67,12 +191,18 @@ func Load() (*Config, error) {
 		GRPCPort:          firstEnv("GRPC_PORT", "METRICS_PORT", "9092"),
 		AppEnv:            getEnv("APP_ENV", "development"),
 		LogLevel:          getEnv("LOG_LEVEL", "info"),
+		LogFormat:         getEnv("LOG_FORMAT", "json"),
 		KafkaBrokers:      kafkaBrokers,
 		KafkaGroupID:      getEnv("KAFKA_GROUP_ID", "notification-service"),
 		KafkaTopics:       kafkaTopics,
 		WSReadBufferSize:  readBuf,
 		WSWriteBufferSize: writeBuf,
 		WSSendQueueSize:   sendQueue,
+
+		WSCompression:      getEnvBool("WS_COMPRESSION", false),
+		WSCompressionLevel: compressionLevel,
+		WSBatchMaxWindow:   batchMaxWindow,
+		WSBatchMaxMessages: batchMaxMessages,
 	}
 	cfg.DB.Host = getEnv("DB_HOST", "localhost")
 	cfg.DB.Port = getEnv("DB_PORT", "5432")
@@ -80,6 +210,100 @@ func Load() (*Config, error) {
 	cfg.DB.Password = getEnv("DB_PASSWORD", "postgres")
 	cfg.DB.Database = getEnv("DB_DATABASE", "notification_service")
 	cfg.DB.SSLMode = getEnv("DB_SSLMODE", "disable")
+	cfg.DB.AutoMigrate = getEnvBool("DB_AUTO_MIGRATE", false)
+
+	cfg.Email.Enabled = getEnvBool("EMAIL_ENABLED", false)
+	cfg.Email.Policy = getEnv("EMAIL_POLICY", "*:offline")
+	cfg.Email.Transport = getEnv("EMAIL_TRANSPORT", "smtp")
+	cfg.Email.From = getEnv("EMAIL_FROM", "notifications@psds.local")
+	cfg.Email.TemplatesDir = getEnv("EMAIL_TEMPLATES_DIR", "")
+	cfg.Email.MaildirPath = getEnv("EMAIL_MAILDIR", "var/maildir")
+	cfg.SMTP.Host = getEnv("SMTP_HOST", "localhost")
+	cfg.SMTP.Port = getEnv("SMTP_PORT", "587")
+	cfg.SMTP.Username = getEnv("SMTP_USERNAME", "")
+	cfg.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+
+	cfg.Push.Enabled = getEnvBool("PUSH_ENABLED", false)
+	cfg.Push.Policy = getEnv("PUSH_POLICY", "*:offline")
+	cfg.Push.Fake = getEnvBool("PUSH_FAKE", false)
+	if cfg.Push.Timeout, err = getEnvDuration("PUSH_TIMEOUT", "10s"); err != nil {
+		return nil, err
+	}
+	cfg.Push.FCMCredentialsFile = getEnv("FCM_CREDENTIALS_FILE", "")
+	cfg.Push.FCMProjectID = getEnv("FCM_PROJECT_ID", "")
+	cfg.Push.APNsKeyFile = getEnv("APNS_KEY_FILE", "")
+	cfg.Push.APNsKeyID = getEnv("APNS_KEY_ID", "")
+	cfg.Push.APNsTeamID = getEnv("APNS_TEAM_ID", "")
+	cfg.Push.APNsTopic = getEnv("APNS_TOPIC", "")
+	cfg.Push.APNsSandbox = getEnvBool("APNS_SANDBOX", false)
+
+	cfg.Webhook.Enabled = getEnvBool("WEBHOOK_ENABLED", false)
+	if cfg.Webhook.Timeout, err = getEnvDuration("WEBHOOK_TIMEOUT", "10s"); err != nil {
+		return nil, err
+	}
+	if cfg.Webhook.BreakerCooldown, err = getEnvDuration("WEBHOOK_BREAKER_COOLDOWN", "1m"); err != nil {
+		return nil, err
+	}
+	cfg.Webhook.MaxAttempts, _ = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	cfg.Webhook.Concurrency, _ = strconv.Atoi(getEnv("WEBHOOK_CONCURRENCY", "8"))
+	cfg.Webhook.BreakerThreshold, _ = strconv.Atoi(getEnv("WEBHOOK_BREAKER_THRESHOLD", "5"))
+
+	if cfg.Delivery.PollInterval, err = getEnvDuration("DELIVERY_POLL_INTERVAL", "2s"); err != nil {
+		return nil, err
+	}
+	if cfg.Delivery.RetryBase, err = getEnvDuration("DELIVERY_RETRY_BASE", "30s"); err != nil {
+		return nil, err
+	}
+	if cfg.Delivery.RetryMax, err = getEnvDuration("DELIVERY_RETRY_MAX", "1h"); err != nil {
+		return nil, err
+	}
+	cfg.Delivery.MaxAttempts, _ = strconv.Atoi(getEnv("DELIVERY_MAX_ATTEMPTS", "6"))
+
+	if cfg.Digest.PollInterval, err = getEnvDuration("DIGEST_POLL_INTERVAL", "30s"); err != nil {
+		return nil, err
+	}
+	cfg.Digest.MaxEvents, _ = strconv.Atoi(getEnv("DIGEST_MAX_EVENTS", "1000"))
+
+	cfg.Scheduler.Enabled = getEnvBool("SCHEDULER_ENABLED", true)
+	if cfg.Scheduler.PollInterval, err = getEnvDuration("SCHEDULER_POLL_INTERVAL", "1s"); err != nil {
+		return nil, err
+	}
+	cfg.Retention.Enabled = getEnvBool("RETENTION_ENABLED", false)
+	cfg.Retention.Policy = getEnv("RETENTION_POLICY", "")
+	if cfg.Retention.Interval, err = getEnvDuration("RETENTION_INTERVAL", "1h"); err != nil {
+		return nil, err
+	}
+	if cfg.Retention.Pause, err = getEnvDuration("RETENTION_BATCH_PAUSE", "100ms"); err != nil {
+		return nil, err
+	}
+	cfg.Retention.BatchSize, _ = strconv.Atoi(getEnv("RETENTION_BATCH_SIZE", "1000"))
+	cfg.Retention.Archive = getEnvBool("RETENTION_ARCHIVE", false)
+	if cfg.WSDrainTimeout, err = getEnvDuration("WS_DRAIN_TIMEOUT", "10s"); err != nil {
+		return nil, err
+	}
+	if cfg.WSReconnectHint, err = getEnvDuration("WS_RECONNECT_HINT", "5s"); err != nil {
+		return nil, err
+	}
+	cfg.RedisURL = strings.TrimSpace(getEnv("REDIS_URL", ""))
+	cfg.AdminToken = strings.TrimSpace(getEnv("ADMIN_TOKEN", ""))
+	if cfg.Ready.CheckTimeout, err = getEnvDuration("READY_CHECK_TIMEOUT", "2s"); err != nil {
+		return nil, err
+	}
+	for _, s := range strings.Split(getEnv("READY_CRITICAL", "postgres"), ",") {
+		if t := strings.TrimSpace(s); t != "" {
+			cfg.Ready.Critical = append(cfg.Ready.Critical, t)
+		}
+	}
+	cfg.Tracing.Exporter = strings.ToLower(getEnv("TRACING_EXPORTER", "none"))
+	cfg.Tracing.File = getEnv("TRACING_FILE", "traces.jsonl")
+	if cfg.Tracing.SampleRatio, err = strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64); err != nil {
+		return nil, fmt.Errorf("config: TRACING_SAMPLE_RATIO: %w", err)
+	}
+	cfg.Templates.Dir = getEnv("TEMPLATES_DIR", "")
+	cfg.Templates.DefaultLocale = getEnv("TEMPLATES_DEFAULT_LOCALE", "ru")
+	if cfg.Templates.ReloadInterval, err = getEnvDuration("TEMPLATES_RELOAD_INTERVAL", "30s"); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -87,6 +311,23 @@ func (c *Config) Validate() error {
 	if len(c.KafkaBrokers) == 0 && len(c.KafkaTopics) > 0 {
 		return errors.New("config: KAFKA_BROKERS required when KAFKA_TOPICS set")
 	}
+	if c.WSCompressionLevel < flate.HuffmanOnly || c.WSCompressionLevel > flate.BestCompression {
+		return errors.New("config: WS_COMPRESSION_LEVEL must be between -2 and 9")
+	}
+	if c.Email.Enabled && c.Email.Transport != "smtp" && c.Email.Transport != "maildir" {
+		return errors.New("config: EMAIL_TRANSPORT must be smtp or maildir")
+	}
+	if c.Push.Enabled && !c.Push.Fake && c.Push.FCMCredentialsFile == "" && c.Push.APNsKeyFile == "" {
+		return errors.New("config: PUSH_ENABLED requires FCM_CREDENTIALS_FILE, APNS_KEY_FILE or PUSH_FAKE=true")
+	}
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		return errors.New("config: TRACING_EXPORTER must be none, otlp, stdout or file")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return errors.New("config: TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
 	return nil
 }
 
@@ -118,6 +359,22 @@ func firstEnv(keysAndDef ...string) string {
 	return def
 }
 
+func getEnvDuration(key, def string) (time.Duration, error) {
+	d, err := time.ParseDuration(getEnv(key, def))
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getEnvBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
 func getEnv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/routing"
+)
+
+// Outbox реализует routing.Outbox поверх notification_events/notification_deliveries.
+type Outbox struct {
+	repo *repository.DeliveryRepository
+}
+
+func NewOutbox(repo *repository.DeliveryRepository) *Outbox {
+	return &Outbox{repo: repo}
+}
+
+func (o *Outbox) Enqueue(ctx context.Context, channel string, notificationID uuid.UUID, msg routing.Message, userIDs []uuid.UUID, notBefore time.Time) error {
+	var sessionID uuid.NullUUID
+	if sid, ok := msg.Session(); ok {
+		sessionID = uuid.NullUUID{UUID: sid, Valid: true}
+	}
+	return o.repo.Enqueue(ctx, channel, notificationID, sessionID, msg.Event, msg.Payload, userIDs, notBefore)
+}
@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// Sender отправляет одну доставку через внешний канал.
+type Sender interface {
+	Send(ctx context.Context, d repository.Delivery) error
+}
+
+// permanentError — ошибка, после которой повторять доставку бессмысленно.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку Sender как окончательную: доставка сразу получает статус failed.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка через Permanent.
+func IsPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
+
+// Config — параметры обработки очереди доставок.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+	SendTimeout  time.Duration
+}
+
+// Worker разбирает notification_deliveries и отправляет доставки через зарегистрированные каналы,
+// повторяя неуспешные попытки с экспоненциальной задержкой.
+type Worker struct {
+	repo    *repository.DeliveryRepository
+	senders map[string]Sender
+	cfg     Config
+}
+
+func NewWorker(repo *repository.DeliveryRepository, senders map[string]Sender, cfg Config) *Worker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 6
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = time.Hour
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 30 * time.Second
+	}
+	return &Worker{repo: repo, senders: senders, cfg: cfg}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (w *Worker) Run(ctx context.Context) {
+	if len(w.senders) == 0 {
+		return
+	}
+	channels := make([]string, 0, len(w.senders))
+	for name := range w.senders {
+		channels = append(channels, name)
+	}
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			// Lease больше таймаута отправки, чтобы доставку не забрала другая реплика во время попытки.
+			batch, err := w.repo.ClaimDue(ctx, channels, w.cfg.BatchSize, 2*w.cfg.SendTimeout)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "delivery: claim", "error", err)
+				}
+				break
+			}
+			for _, d := range batch {
+				w.process(ctx, d)
+			}
+			if len(batch) < w.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, d repository.Delivery) {
+	sender := w.senders[d.Channel]
+	sendCtx, cancel := context.WithTimeout(ctx, w.cfg.SendTimeout)
+	err := sender.Send(sendCtx, d)
+	cancel()
+
+	switch {
+	case err == nil:
+		err = w.repo.MarkDelivered(ctx, d.ID)
+	case IsPermanent(err) || d.Attempts >= w.cfg.MaxAttempts:
+		slog.WarnContext(ctx, "delivery: failed", "channel", d.Channel, "delivery_id", d.ID, "user_id", d.Event.UserID, "attempts", d.Attempts, "error", err)
+		err = w.repo.MarkFailed(ctx, d.ID, err.Error())
+	default:
+		err = w.repo.MarkRetry(ctx, d.ID, time.Now().Add(Backoff(w.cfg.RetryBase, w.cfg.RetryMax, d.Attempts)), err.Error())
+	}
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "delivery: update", "delivery_id", d.ID, "error", err)
+	}
+}
+
+// Backoff — экспоненциальная задержка перед попыткой attempt+1 с jitter ±10%, ограниченная max.
+func Backoff(base, max time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	jitter := time.Duration(rand.Int64N(int64(d)/5+1)) - d/10
+	return d + jitter
+}
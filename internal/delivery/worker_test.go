@@ -0,0 +1,31 @@
+package delivery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := time.Second, time.Minute
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		// Разброс — ±10% от задержки.
+		lo, hi := tt.want-tt.want/10, tt.want+tt.want/10
+		for range 100 {
+			if got := Backoff(base, max, tt.attempt); got < lo || got > hi {
+				t.Fatalf("Backoff(attempt %d) = %s, want %s..%s", tt.attempt, got, lo, hi)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package digest
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/routing"
+)
+
+// Collector реализует routing.DigestCollector поверх notification_digests/notification_events.
+type Collector struct {
+	repo *repository.DigestRepository
+}
+
+func NewCollector(repo *repository.DigestRepository) *Collector {
+	return &Collector{repo: repo}
+}
+
+// Collect откладывает событие в дайджест тех получателей, у кого оно подпадает под настройку дайджеста,
+// и возвращает этих получателей: остальные каналы им событие не доставляют.
+func (c *Collector) Collect(ctx context.Context, msg routing.Message, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	settings, err := c.repo.ForUsers(ctx, userIDs)
+	if err != nil || len(settings) == 0 {
+		return nil, err
+	}
+	priority := strings.ToLower(strings.TrimSpace(msg.Priority))
+	collected := make(map[uuid.UUID]bool)
+	var targets []uuid.UUID
+	for uid, d := range settings {
+		if matches(d, msg.Event, priority) {
+			collected[uid] = true
+			targets = append(targets, uid)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	var sessionID uuid.NullUUID
+	if sid, ok := msg.Session(); ok {
+		sessionID = uuid.NullUUID{UUID: sid, Valid: true}
+	}
+	if err := c.repo.Collect(ctx, sessionID, msg.Event, msg.Payload, targets); err != nil {
+		return nil, err
+	}
+	return collected, nil
+}
+
+// matches сообщает, собирается ли событие с данным приоритетом в дайджест d.
+// Критичные события в дайджест не попадают никогда.
+func matches(d repository.Digest, eventType, priority string) bool {
+	if priority == routing.PriorityCritical {
+		return false
+	}
+	if len(d.Events) == 0 {
+		return priority == routing.PriorityLow
+	}
+	return slices.Contains(d.Events, eventType)
+}
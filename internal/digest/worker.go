@@ -0,0 +1,200 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/service"
+)
+
+// EventType — тип события дайджеста (WebSocket-кадр, email-шаблон, push).
+const EventType = "psds.digest"
+
+// maxItems — сколько последних событий дайджест передаёт целиком; остальные учитываются только в счётчиках.
+const maxItems = 50
+
+// Payload — содержимое дайджеста: title/body для push и email, счётчики по типам событий и последние события.
+type Payload struct {
+	Title  string       `json:"title"`
+	Body   string       `json:"body"`
+	Count  int          `json:"count"`
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Events []EventCount `json:"events"`
+	Items  []Item       `json:"items"`
+}
+
+// EventCount — число событий одного типа в дайджесте.
+type EventCount struct {
+	Event string `json:"event"`
+	Count int    `json:"count"`
+}
+
+// Item — событие, вошедшее в дайджест.
+type Item struct {
+	ID        uuid.UUID       `json:"id"`
+	Event     string          `json:"event"`
+	SessionID *uuid.UUID      `json:"session_id,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Build собирает дайджест из событий, упорядоченных по времени.
+func Build(events []repository.Event) Payload {
+	p := Payload{Count: len(events), Events: []EventCount{}, Items: []Item{}}
+	if len(events) == 0 {
+		return p
+	}
+	p.From = events[0].CreatedAt.Truncate(time.Second)
+	p.To = events[len(events)-1].CreatedAt.Truncate(time.Second)
+	counts := make(map[string]int)
+	for _, ev := range events {
+		counts[ev.EventType]++
+	}
+	for event, n := range counts {
+		p.Events = append(p.Events, EventCount{Event: event, Count: n})
+	}
+	sort.Slice(p.Events, func(i, j int) bool {
+		if p.Events[i].Count != p.Events[j].Count {
+			return p.Events[i].Count > p.Events[j].Count
+		}
+		return p.Events[i].Event < p.Events[j].Event
+	})
+	start := max(0, len(events)-maxItems)
+	for _, ev := range events[start:] {
+		item := Item{ID: ev.ID, Event: ev.EventType, Payload: ev.Payload, CreatedAt: ev.CreatedAt}
+		if ev.SessionID.Valid {
+			sid := ev.SessionID.UUID
+			item.SessionID = &sid
+		}
+		p.Items = append(p.Items, item)
+	}
+	parts := make([]string, 0, len(p.Events))
+	for _, c := range p.Events {
+		parts = append(parts, fmt.Sprintf("%s: %d", c.Event, c.Count))
+	}
+	p.Title = fmt.Sprintf("Сводка уведомлений: %d", p.Count)
+	p.Body = strings.Join(parts, ", ")
+	return p
+}
+
+// Config — параметры сборки дайджестов.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxEvents — сколько событий максимум входит в один дайджест; остаток уйдёт в следующий.
+	MaxEvents int
+}
+
+// Worker по расписанию собирает накопленные события в дайджест и отправляет его через канал,
+// выбранный пользователем: WebSocket — сразу (если пользователь онлайн), email/push — через очередь доставок.
+type Worker struct {
+	repo       *repository.DigestRepository
+	deliveries *repository.DeliveryRepository
+	hub        *service.NotifyHub
+	external   map[string]bool
+	quietHours *repository.QuietHoursRepository
+	cfg        Config
+}
+
+// NewWorker создаёт worker; external — включённые внешние каналы (email, push). Дайджест с выключенным
+// каналом отправляется через WebSocket. В окно тишины (quietHours) email/push-дайджест не собирается:
+// события копятся до его окончания.
+func NewWorker(repo *repository.DigestRepository, deliveries *repository.DeliveryRepository, hub *service.NotifyHub, quietHours *repository.QuietHoursRepository, external []string, cfg Config) *Worker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = 1000
+	}
+	ext := make(map[string]bool, len(external))
+	for _, c := range external {
+		ext[c] = true
+	}
+	return &Worker{repo: repo, deliveries: deliveries, hub: hub, quietHours: quietHours, external: ext, cfg: cfg}
+}
+
+// Run собирает дайджесты до отмены ctx.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			batch, err := w.repo.ClaimDue(ctx, w.cfg.BatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "digest: claim", "error", err)
+				}
+				break
+			}
+			for _, d := range batch {
+				if err := w.send(ctx, d); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "digest: send", "user_id", d.UserID, "error", err)
+				}
+			}
+			if len(batch) < w.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) send(ctx context.Context, d repository.Digest) error {
+	channel := d.Channel
+	if channel != repository.ChannelWebSocket && !w.external[channel] {
+		channel = repository.ChannelWebSocket
+	}
+	if channel == repository.ChannelWebSocket && !w.hub.IsOnline(d.UserID) {
+		// Офлайн-пользователь получит накопленное в одном из следующих дайджестов.
+		return nil
+	}
+	if channel != repository.ChannelWebSocket && w.quietHours != nil {
+		quiet, err := w.quietHours.QuietUntil(ctx, []uuid.UUID{d.UserID}, time.Now())
+		if err != nil {
+			return err
+		}
+		if _, ok := quiet[d.UserID]; ok {
+			return nil
+		}
+	}
+
+	events, err := w.repo.PendingEvents(ctx, d.UserID, w.cfg.MaxEvents)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	payload, err := json.Marshal(Build(events))
+	if err != nil {
+		return err
+	}
+
+	if channel == repository.ChannelWebSocket {
+		frame, err := json.Marshal(map[string]interface{}{"event": EventType, "payload": json.RawMessage(payload)})
+		if err != nil {
+			return err
+		}
+		w.hub.SendToUser(d.UserID, frame)
+	} else if err := w.deliveries.Enqueue(ctx, channel, uuid.New(), uuid.NullUUID{}, EventType, payload, []uuid.UUID{d.UserID}, time.Time{}); err != nil {
+		return fmt.Errorf("enqueue %s: %w", channel, err)
+	}
+
+	ids := make([]uuid.UUID, len(events))
+	for i, ev := range events {
+		ids[i] = ev.ID
+	}
+	return w.repo.MarkSent(ctx, ids)
+}
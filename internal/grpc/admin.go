@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/adminauth"
+	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ConnectionAdmin — подключения WebSocket этой реплики (реализует service.NotifyHub).
+type ConnectionAdmin interface {
+	Connections() []service.ConnectionInfo
+	DisconnectConn(connID uuid.UUID, reason string) bool
+	DisconnectUser(userID uuid.UUID, reason string) bool
+	SessionSubscribers(sessionID uuid.UUID) []uuid.UUID
+	UnsubscribeSession(sessionID, userID uuid.UUID) bool
+}
+
+// AdminServer implements notification_service.NotificationAdminServiceServer.
+// Каждый вызов требует metadata authorization: Bearer <Token> (через grpc-gateway — заголовок Authorization).
+type AdminServer struct {
+	notification_service.UnimplementedNotificationAdminServiceServer
+	Hub   ConnectionAdmin
+	Token string
+}
+
+// NewAdminServer создаёт admin-сервер; пустой token выключает admin API.
+func NewAdminServer(hub ConnectionAdmin, token string) *AdminServer {
+	return &AdminServer{Hub: hub, Token: token}
+}
+
+// defaultDisconnectReason — причина в close-кадре, если она не передана.
+const defaultDisconnectReason = "disconnected by admin"
+
+// maxCloseReasonLength — ограничение WebSocket на причину в close-кадре (125 байт минус код).
+const maxCloseReasonLength = 123
+
+func (s *AdminServer) authorize(ctx context.Context) error {
+	if s.Token == "" || s.Hub == nil {
+		return status.Error(codes.Unavailable, "admin api is not configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if adminauth.Valid(md.Get("authorization"), s.Token) {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, "invalid admin token")
+}
+
+func (s *AdminServer) ListConnections(ctx context.Context, req *notification_service.ListConnectionsRequest) (*notification_service.ListConnectionsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	var userID, sessionID uuid.UUID
+	var err error
+	if v := req.GetUserId(); v != "" {
+		if userID, err = uuid.Parse(v); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user id")
+		}
+	}
+	if v := req.GetSessionId(); v != "" {
+		if sessionID, err = uuid.Parse(v); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid session id")
+		}
+	}
+	resp := &notification_service.ListConnectionsResponse{}
+	for _, c := range s.Hub.Connections() {
+		switch {
+		case userID != uuid.Nil && c.UserID != userID,
+			sessionID != uuid.Nil && !slices.Contains(c.Sessions, sessionID),
+			req.GetRegion() != "" && c.Meta.Region != req.GetRegion(),
+			req.GetRole() != "" && !slices.Contains(c.Meta.Roles, req.GetRole()):
+			continue
+		}
+		resp.Connections = append(resp.Connections, connectionToProto(c))
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) DisconnectConnection(ctx context.Context, req *notification_service.DisconnectConnectionRequest) (*notification_service.DisconnectResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	connID, err := uuid.Parse(req.GetConnId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid conn id")
+	}
+	reason, err := disconnectReason(req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+	if !s.Hub.DisconnectConn(connID, reason) {
+		return nil, status.Error(codes.NotFound, "connection not found")
+	}
+	slog.InfoContext(ctx, "admin: connection disconnected", "conn_id", connID, "reason", reason)
+	return &notification_service.DisconnectResponse{Ok: true}, nil
+}
+
+func (s *AdminServer) DisconnectUser(ctx context.Context, req *notification_service.DisconnectUserRequest) (*notification_service.DisconnectResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	reason, err := disconnectReason(req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+	if !s.Hub.DisconnectUser(userID, reason) {
+		return nil, status.Error(codes.NotFound, "user is not connected")
+	}
+	slog.InfoContext(ctx, "admin: user disconnected", "user_id", userID, "reason", reason)
+	return &notification_service.DisconnectResponse{Ok: true}, nil
+}
+
+func (s *AdminServer) ListSessionSubscribers(ctx context.Context, req *notification_service.ListSessionSubscribersRequest) (*notification_service.ListSessionSubscribersResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session id")
+	}
+	resp := &notification_service.ListSessionSubscribersResponse{}
+	for _, uid := range s.Hub.SessionSubscribers(sessionID) {
+		resp.UserIds = append(resp.UserIds, uid.String())
+	}
+	slices.Sort(resp.UserIds)
+	return resp, nil
+}
+
+func (s *AdminServer) UnsubscribeSession(ctx context.Context, req *notification_service.UnsubscribeSessionRequest) (*notification_service.UnsubscribeSessionResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session id")
+	}
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if !s.Hub.UnsubscribeSession(sessionID, userID) {
+		return nil, status.Error(codes.NotFound, "user is not subscribed to the session")
+	}
+	slog.InfoContext(ctx, "admin: session unsubscribed", "session_id", sessionID, "user_id", userID)
+	return &notification_service.UnsubscribeSessionResponse{Ok: true}, nil
+}
+
+func disconnectReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return defaultDisconnectReason, nil
+	}
+	if len(reason) > maxCloseReasonLength {
+		return "", status.Error(codes.InvalidArgument, "reason must be at most 123 bytes")
+	}
+	return reason, nil
+}
+
+func connectionToProto(c service.ConnectionInfo) *notification_service.Connection {
+	out := &notification_service.Connection{
+		ConnId:      c.ID.String(),
+		UserId:      c.UserID.String(),
+		Region:      c.Meta.Region,
+		Roles:       c.Meta.Roles,
+		QueueDepth:  int32(c.QueueDepth),
+		QueueSize:   int32(c.QueueSize),
+		ConnectedAt: timestamppb.New(c.ConnectedAt),
+		RemoteAddr:  c.Meta.RemoteAddr,
+		Subprotocol: c.Meta.Subprotocol,
+		Locale:      c.Meta.Locale,
+	}
+	for _, sid := range c.Sessions {
+		out.SessionIds = append(out.SessionIds, sid.String())
+	}
+	slices.Sort(out.SessionIds)
+	return out
+}
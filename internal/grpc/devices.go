@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeviceStore — реестр push-токенов устройств.
+type DeviceStore interface {
+	Register(ctx context.Context, userID uuid.UUID, platform, token string) error
+	Unregister(ctx context.Context, userID uuid.UUID, token string) error
+}
+
+// maxDeviceTokenLength — с запасом больше длины токенов FCM (~160) и APNs (64 hex).
+const maxDeviceTokenLength = 4096
+
+func (s *Server) RegisterDevice(ctx context.Context, req *notification_service.RegisterDeviceRequest) (*notification_service.RegisterDeviceResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	platform := strings.ToLower(strings.TrimSpace(req.GetPlatform()))
+	switch platform {
+	case repository.PlatformAndroid, repository.PlatformIOS, repository.PlatformWeb:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "platform must be android, ios or web")
+	}
+	token := strings.TrimSpace(req.GetToken())
+	if token == "" || len(token) > maxDeviceTokenLength || strings.ContainsAny(token, " \t\r\n/") {
+		return nil, status.Error(codes.InvalidArgument, "invalid token")
+	}
+	if platform == repository.PlatformIOS {
+		if _, err := hex.DecodeString(token); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "ios token must be hex-encoded")
+		}
+	}
+	if s.Devices == nil {
+		return nil, status.Error(codes.Unavailable, "device registry is not configured")
+	}
+	if err := s.Devices.Register(ctx, userID, platform, token); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.RegisterDeviceResponse{Ok: true}, nil
+}
+
+func (s *Server) UnregisterDevice(ctx context.Context, req *notification_service.UnregisterDeviceRequest) (*notification_service.UnregisterDeviceResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	token := strings.TrimSpace(req.GetToken())
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if s.Devices == nil {
+		return nil, status.Error(codes.Unavailable, "device registry is not configured")
+	}
+	if err := s.Devices.Unregister(ctx, userID, token); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.UnregisterDeviceResponse{Ok: true}, nil
+}
@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DigestStore — настройки дайджестов пользователей.
+type DigestStore interface {
+	Set(ctx context.Context, d repository.Digest) (repository.Digest, error)
+	Get(ctx context.Context, userID uuid.UUID) (repository.Digest, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// maxDigestInterval — самый редкий дайджест — раз в сутки.
+const maxDigestInterval = 24 * 60
+
+func (s *Server) GetDigest(ctx context.Context, req *notification_service.GetDigestRequest) (*notification_service.Digest, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.Digests == nil {
+		return nil, status.Error(codes.Unavailable, "digest store is not configured")
+	}
+	d, err := s.Digests.Get(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return digestToProto(d), nil
+}
+
+func (s *Server) SetDigest(ctx context.Context, req *notification_service.SetDigestRequest) (*notification_service.Digest, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	minutes := req.GetIntervalMinutes()
+	if minutes <= 0 || minutes > maxDigestInterval {
+		return nil, status.Error(codes.InvalidArgument, "interval_minutes must be between 1 and 1440")
+	}
+	channel := strings.ToLower(strings.TrimSpace(req.GetChannel()))
+	switch channel {
+	case "":
+		channel = repository.ChannelWebSocket
+	case repository.ChannelWebSocket, repository.ChannelEmail, repository.ChannelPush:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "channel must be websocket, email or push")
+	}
+	var events []string
+	for _, e := range req.GetEvents() {
+		e = strings.TrimSpace(e)
+		if e == "" || len(e) > maxEventTypeLength || strings.ContainsAny(e, " \t\r\n") {
+			return nil, status.Error(codes.InvalidArgument, "invalid event type")
+		}
+		events = append(events, e)
+	}
+	if s.Digests == nil {
+		return nil, status.Error(codes.Unavailable, "digest store is not configured")
+	}
+	d, err := s.Digests.Set(ctx, repository.Digest{
+		UserID:   userID,
+		Interval: time.Duration(minutes) * time.Minute,
+		Channel:  channel,
+		Events:   events,
+	})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return digestToProto(d), nil
+}
+
+func (s *Server) DeleteDigest(ctx context.Context, req *notification_service.DeleteDigestRequest) (*notification_service.DeleteDigestResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.Digests == nil {
+		return nil, status.Error(codes.Unavailable, "digest store is not configured")
+	}
+	if err := s.Digests.Delete(ctx, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.DeleteDigestResponse{Ok: true}, nil
+}
+
+func digestToProto(d repository.Digest) *notification_service.Digest {
+	out := &notification_service.Digest{
+		UserId:          d.UserID.String(),
+		IntervalMinutes: int32(d.Interval / time.Minute),
+		Channel:         d.Channel,
+		Events:          d.Events,
+	}
+	if !d.NextRunAt.IsZero() {
+		out.NextRunAt = timestamppb.New(d.NextRunAt)
+	}
+	if !d.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(d.UpdatedAt)
+	}
+	return out
+}
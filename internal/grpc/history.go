@@ -0,0 +1,196 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// HistoryStore — история и входящие уведомления пользователей (notification_events).
+type HistoryStore interface {
+	List(ctx context.Context, q repository.HistoryQuery) ([]repository.Notification, error)
+	Mark(ctx context.Context, userID uuid.UUID, state string, notificationIDs []uuid.UUID) (int64, error)
+	MarkBefore(ctx context.Context, userID uuid.UUID, state string, before time.Time) (int64, error)
+	UnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// maxHistoryEventTypes — сколько типов событий можно передать в фильтре.
+const maxHistoryEventTypes = 20
+
+// maxMarkNotifications — сколько уведомлений можно отметить одним запросом по id.
+const maxMarkNotifications = 500
+
+func (s *Server) ListNotifications(ctx context.Context, req *notification_service.ListNotificationsRequest) (*notification_service.ListNotificationsResponse, error) {
+	var q repository.HistoryQuery
+	if v := req.GetUserId(); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user id")
+		}
+		q.UserID = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+	if v := req.GetSessionId(); v != "" {
+		sessionID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid session id")
+		}
+		q.SessionID = uuid.NullUUID{UUID: sessionID, Valid: true}
+	}
+	if !q.UserID.Valid && !q.SessionID.Valid {
+		return nil, status.Error(codes.InvalidArgument, "user_id or session_id is required")
+	}
+	if len(req.GetEventTypes()) > maxHistoryEventTypes {
+		return nil, status.Error(codes.InvalidArgument, "too many event types")
+	}
+	for _, e := range req.GetEventTypes() {
+		e = strings.TrimSpace(e)
+		if e == "" || len(e) > maxEventTypeLength {
+			return nil, status.Error(codes.InvalidArgument, "invalid event type")
+		}
+		q.EventTypes = append(q.EventTypes, e)
+	}
+	if req.GetSince() != nil {
+		if err := req.GetSince().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid since")
+		}
+		q.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		if err := req.GetUntil().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid until")
+		}
+		q.Until = req.GetUntil().AsTime()
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Since.Before(q.Until) {
+		return nil, status.Error(codes.InvalidArgument, "since must be before until")
+	}
+	after, err := repository.DecodeCursor(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	q.After = after
+	q.UnreadOnly, q.ExcludeDismissed = req.GetUnreadOnly(), req.GetExcludeDismissed()
+	if s.History == nil {
+		return nil, status.Error(codes.Unavailable, "history store is not configured")
+	}
+	limit := pageSize(req.GetPageSize())
+	q.Limit = limit + 1
+	list, err := s.History.List(ctx, q)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &notification_service.ListNotificationsResponse{}
+	if len(list) > limit {
+		list = list[:limit]
+		last := list[len(list)-1]
+		resp.NextPageToken = repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, n := range list {
+		resp.Notifications = append(resp.Notifications, notificationToProto(n))
+	}
+	return resp, nil
+}
+
+func (s *Server) MarkNotifications(ctx context.Context, req *notification_service.MarkNotificationsRequest) (*notification_service.MarkNotificationsResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	state := strings.ToLower(strings.TrimSpace(req.GetState()))
+	switch state {
+	case repository.StateSeen, repository.StateRead, repository.StateDismissed:
+	default:
+		return nil, status.Error(codes.InvalidArgument, repository.ErrInvalidState.Error())
+	}
+	ids := req.GetNotificationIds()
+	switch {
+	case len(ids) > 0 && req.GetBefore() != nil:
+		return nil, status.Error(codes.InvalidArgument, "notification_ids and before are mutually exclusive")
+	case len(ids) == 0 && req.GetBefore() == nil:
+		return nil, status.Error(codes.InvalidArgument, "notification_ids or before is required")
+	case len(ids) > maxMarkNotifications:
+		return nil, status.Error(codes.InvalidArgument, "too many notification_ids")
+	}
+	notificationIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		nid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid notification id")
+		}
+		notificationIDs = append(notificationIDs, nid)
+	}
+	if req.GetBefore() != nil {
+		if err := req.GetBefore().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid before")
+		}
+	}
+	if s.History == nil {
+		return nil, status.Error(codes.Unavailable, "history store is not configured")
+	}
+	var updated int64
+	if req.GetBefore() != nil {
+		updated, err = s.History.MarkBefore(ctx, userID, state, req.GetBefore().AsTime())
+	} else {
+		updated, err = s.History.Mark(ctx, userID, state, notificationIDs)
+	}
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	unread, err := s.History.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.MarkNotificationsResponse{Updated: int32(updated), UnreadCount: int32(unread)}, nil
+}
+
+func (s *Server) GetUnreadCount(ctx context.Context, req *notification_service.GetUnreadCountRequest) (*notification_service.GetUnreadCountResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.History == nil {
+		return nil, status.Error(codes.Unavailable, "history store is not configured")
+	}
+	unread, err := s.History.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.GetUnreadCountResponse{UnreadCount: int32(unread)}, nil
+}
+
+func notificationToProto(n repository.Notification) *notification_service.Notification {
+	out := &notification_service.Notification{
+		Id:        n.NotificationID.String(),
+		UserId:    n.UserID.String(),
+		Event:     n.EventType,
+		CreatedAt: timestamppb.New(n.CreatedAt),
+	}
+	if n.SessionID.Valid {
+		out.SessionId = n.SessionID.UUID.String()
+	}
+	if n.SeenAt != nil {
+		out.SeenAt = timestamppb.New(*n.SeenAt)
+	}
+	if n.ReadAt != nil {
+		out.ReadAt = timestamppb.New(*n.ReadAt)
+	}
+	if n.DismissedAt != nil {
+		out.DismissedAt = timestamppb.New(*n.DismissedAt)
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(n.Payload, &m) == nil {
+		if st, err := structpb.NewStruct(m); err == nil {
+			out.Payload = st
+		}
+	}
+	return out
+}
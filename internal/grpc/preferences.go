@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PreferenceStore — настройки уведомлений пользователей.
+type PreferenceStore interface {
+	Set(ctx context.Context, p repository.Preference) (repository.Preference, error)
+	Get(ctx context.Context, userID uuid.UUID, eventType string) (repository.Preference, error)
+	List(ctx context.Context, userID uuid.UUID) ([]repository.Preference, error)
+	Delete(ctx context.Context, userID uuid.UUID, eventType string) error
+}
+
+// maxEventTypeLength — размер колонки event_type.
+const maxEventTypeLength = 64
+
+func (s *Server) ListPreferences(ctx context.Context, req *notification_service.ListPreferencesRequest) (*notification_service.ListPreferencesResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.Preferences == nil {
+		return nil, status.Error(codes.Unavailable, "preference store is not configured")
+	}
+	list, err := s.Preferences.List(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &notification_service.ListPreferencesResponse{Preferences: make([]*notification_service.Preference, 0, len(list))}
+	for _, p := range list {
+		resp.Preferences = append(resp.Preferences, preferenceToProto(p))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetPreference(ctx context.Context, req *notification_service.GetPreferenceRequest) (*notification_service.Preference, error) {
+	userID, eventType, err := parsePreferenceKey(req.GetUserId(), req.GetEventType())
+	if err != nil {
+		return nil, err
+	}
+	if s.Preferences == nil {
+		return nil, status.Error(codes.Unavailable, "preference store is not configured")
+	}
+	p, err := s.Preferences.Get(ctx, userID, eventType)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return preferenceToProto(p), nil
+}
+
+func (s *Server) SetPreference(ctx context.Context, req *notification_service.SetPreferenceRequest) (*notification_service.Preference, error) {
+	userID, eventType, err := parsePreferenceKey(req.GetUserId(), req.GetEventType())
+	if err != nil {
+		return nil, err
+	}
+	channels, err := validateChannels(req.GetChannels())
+	if err != nil {
+		return nil, err
+	}
+	if s.Preferences == nil {
+		return nil, status.Error(codes.Unavailable, "preference store is not configured")
+	}
+	p, err := s.Preferences.Set(ctx, repository.Preference{
+		UserID:    userID,
+		EventType: eventType,
+		Channels:  channels,
+		Muted:     req.GetMuted(),
+	})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return preferenceToProto(p), nil
+}
+
+func (s *Server) DeletePreference(ctx context.Context, req *notification_service.DeletePreferenceRequest) (*notification_service.DeletePreferenceResponse, error) {
+	userID, eventType, err := parsePreferenceKey(req.GetUserId(), req.GetEventType())
+	if err != nil {
+		return nil, err
+	}
+	if s.Preferences == nil {
+		return nil, status.Error(codes.Unavailable, "preference store is not configured")
+	}
+	if err := s.Preferences.Delete(ctx, userID, eventType); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.DeletePreferenceResponse{Ok: true}, nil
+}
+
+func parsePreferenceKey(rawUserID, rawEventType string) (uuid.UUID, string, error) {
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return uuid.Nil, "", status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	eventType := strings.TrimSpace(rawEventType)
+	if eventType == "" || len(eventType) > maxEventTypeLength || strings.ContainsAny(eventType, " \t\r\n") {
+		return uuid.Nil, "", status.Error(codes.InvalidArgument, "invalid event type")
+	}
+	return userID, eventType, nil
+}
+
+// validateChannels нормализует список каналов; пустой список (все каналы) возвращается как nil.
+func validateChannels(raw []string) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(raw))
+	channels := make([]string, 0, len(raw))
+	for _, c := range raw {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch c {
+		case repository.ChannelWebSocket, repository.ChannelEmail, repository.ChannelPush, repository.ChannelWebhook:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown channel %q: expected websocket, email, push or webhook", c)
+		}
+		if !seen[c] {
+			seen[c] = true
+			channels = append(channels, c)
+		}
+	}
+	return channels, nil
+}
+
+func preferenceToProto(p repository.Preference) *notification_service.Preference {
+	out := &notification_service.Preference{
+		UserId:    p.UserID.String(),
+		EventType: p.EventType,
+		Channels:  p.Channels,
+		Muted:     p.Muted,
+	}
+	if !p.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(p.UpdatedAt)
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// QuietHoursStore — окна тишины пользователей.
+type QuietHoursStore interface {
+	Set(ctx context.Context, q repository.QuietHours) (repository.QuietHours, error)
+	Get(ctx context.Context, userID uuid.UUID) (repository.QuietHours, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+func (s *Server) GetQuietHours(ctx context.Context, req *notification_service.GetQuietHoursRequest) (*notification_service.QuietHours, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.QuietHours == nil {
+		return nil, status.Error(codes.Unavailable, "quiet hours store is not configured")
+	}
+	q, err := s.QuietHours.Get(ctx, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return quietHoursToProto(q), nil
+}
+
+func (s *Server) SetQuietHours(ctx context.Context, req *notification_service.SetQuietHoursRequest) (*notification_service.QuietHours, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	tz := strings.TrimSpace(req.GetTimezone())
+	if tz == "" || len(tz) > 64 {
+		return nil, status.Error(codes.InvalidArgument, "timezone is required")
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "unknown timezone")
+	}
+	start, err := parseClock(req.GetStart())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "start: "+err.Error())
+	}
+	end, err := parseClock(req.GetEnd())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "end: "+err.Error())
+	}
+	if start == end {
+		return nil, status.Error(codes.InvalidArgument, "start and end must differ")
+	}
+	if s.QuietHours == nil {
+		return nil, status.Error(codes.Unavailable, "quiet hours store is not configured")
+	}
+	q, err := s.QuietHours.Set(ctx, repository.QuietHours{UserID: userID, Timezone: tz, Start: start, End: end})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return quietHoursToProto(q), nil
+}
+
+func (s *Server) DeleteQuietHours(ctx context.Context, req *notification_service.DeleteQuietHoursRequest) (*notification_service.DeleteQuietHoursResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	if s.QuietHours == nil {
+		return nil, status.Error(codes.Unavailable, "quiet hours store is not configured")
+	}
+	if err := s.QuietHours.Delete(ctx, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.DeleteQuietHoursResponse{Ok: true}, nil
+}
+
+// parseClock разбирает время "HH:MM" в минуты от полуночи.
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(v))
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func quietHoursToProto(q repository.QuietHours) *notification_service.QuietHours {
+	out := &notification_service.QuietHours{
+		UserId:   q.UserID.String(),
+		Timezone: q.Timezone,
+		Start:    formatClock(q.Start),
+		End:      formatClock(q.End),
+	}
+	if !q.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(q.UpdatedAt)
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ScheduledStore — очередь отложенных уведомлений.
+type ScheduledStore interface {
+	Create(ctx context.Context, eventType string, message json.RawMessage, deliverAt time.Time) (repository.ScheduledNotification, error)
+	Get(ctx context.Context, id uuid.UUID) (repository.ScheduledNotification, error)
+	Cancel(ctx context.Context, id uuid.UUID) (repository.ScheduledNotification, error)
+}
+
+// maxScheduleHorizon — насколько далеко вперёд можно запланировать уведомление.
+const maxScheduleHorizon = 366 * 24 * time.Hour
+
+func (s *Server) ScheduleNotification(ctx context.Context, req *notification_service.ScheduleNotificationRequest) (*notification_service.ScheduledNotification, error) {
+	msg, err := newMessage(req.GetEvent(), req.GetSessionId(), req.GetUserIds(), req.GetRegions(), req.GetRoles(), req.GetPriority())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var deliverAt time.Time
+	switch {
+	case req.GetDeliverAt() != nil && req.GetDelay() != nil:
+		return nil, status.Error(codes.InvalidArgument, "deliver_at and delay are mutually exclusive")
+	case req.GetDeliverAt() != nil:
+		if err := req.GetDeliverAt().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid deliver_at")
+		}
+		deliverAt = req.GetDeliverAt().AsTime()
+	case req.GetDelay() != nil:
+		if err := req.GetDelay().CheckValid(); err != nil || req.GetDelay().AsDuration() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid delay")
+		}
+		deliverAt = now.Add(req.GetDelay().AsDuration())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "deliver_at or delay is required")
+	}
+	if deliverAt.Sub(now) > maxScheduleHorizon {
+		return nil, status.Error(codes.InvalidArgument, "deliver_at is too far in the future")
+	}
+
+	raw, err := marshalMessage(msg, req.GetPayload())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	if s.Scheduled == nil {
+		return nil, status.Error(codes.Unavailable, "scheduler is not configured")
+	}
+	n, err := s.Scheduled.Create(ctx, msg.Event, raw, deliverAt)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return scheduledToProto(n), nil
+}
+
+func (s *Server) GetScheduledNotification(ctx context.Context, req *notification_service.GetScheduledNotificationRequest) (*notification_service.ScheduledNotification, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	if s.Scheduled == nil {
+		return nil, status.Error(codes.Unavailable, "scheduler is not configured")
+	}
+	n, err := s.Scheduled.Get(ctx, id)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return scheduledToProto(n), nil
+}
+
+func (s *Server) CancelScheduledNotification(ctx context.Context, req *notification_service.CancelScheduledNotificationRequest) (*notification_service.ScheduledNotification, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	if s.Scheduled == nil {
+		return nil, status.Error(codes.Unavailable, "scheduler is not configured")
+	}
+	n, err := s.Scheduled.Cancel(ctx, id)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return scheduledToProto(n), nil
+}
+
+func scheduledToProto(n repository.ScheduledNotification) *notification_service.ScheduledNotification {
+	out := &notification_service.ScheduledNotification{
+		Id:        n.ID.String(),
+		Event:     n.EventType,
+		Status:    n.Status,
+		DeliverAt: timestamppb.New(n.DeliverAt),
+		CreatedAt: timestamppb.New(n.CreatedAt),
+	}
+	if n.SentAt != nil {
+		out.SentAt = timestamppb.New(*n.SentAt)
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(n.Message, &m) == nil {
+		if st, err := structpb.NewStruct(m); err == nil {
+			out.Message = st
+		}
+	}
+	return out
+}
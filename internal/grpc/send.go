@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MessageRouter — конвейер маршрутизации событий (реализует routing.Router).
+type MessageRouter interface {
+	Route(ctx context.Context, raw []byte) error
+}
+
+func (s *Server) SendNotification(ctx context.Context, req *notification_service.SendNotificationRequest) (*notification_service.SendNotificationResponse, error) {
+	var raw []byte
+	switch {
+	case len(req.GetRawMessage()) > 0:
+		var err error
+		if raw, err = envelope(req.GetRawMessage()); err != nil {
+			return nil, err
+		}
+	case req.GetMessage() != nil:
+		data, err := req.GetMessage().MarshalJSON()
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid message")
+		}
+		if raw, err = envelope(data); err != nil {
+			return nil, err
+		}
+	default:
+		msg, err := newMessage(req.GetEvent(), req.GetSessionId(), req.GetUserIds(), req.GetRegions(), req.GetRoles(), req.GetPriority())
+		if err != nil {
+			return nil, err
+		}
+		if raw, err = marshalMessage(msg, req.GetPayload()); err != nil {
+			return nil, s.mapError(err)
+		}
+	}
+	if s.Router == nil {
+		return nil, status.Error(codes.Unavailable, "router is not configured")
+	}
+	if err := s.Router.Route(ctx, raw); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.SendNotificationResponse{Ok: true}, nil
+}
+
+// newMessage проверяет событие и получателей и собирает конверт, как его присылает Kafka.
+func newMessage(event, sessionID string, userIDs, regions, roles []string, priority string) (routing.Message, error) {
+	event = strings.TrimSpace(event)
+	if event == "" {
+		return routing.Message{}, status.Error(codes.InvalidArgument, "event is required")
+	}
+	if len(event) > maxEventTypeLength {
+		return routing.Message{}, status.Errorf(codes.InvalidArgument, "event must be at most %d bytes", maxEventTypeLength)
+	}
+	msg := routing.Message{Event: event, Regions: regions, Roles: roles}
+	if sessionID != "" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			return msg, status.Error(codes.InvalidArgument, "invalid session id")
+		}
+		msg.SessionID = sessionID
+	}
+	for _, id := range userIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return msg, status.Error(codes.InvalidArgument, "invalid user id")
+		}
+		msg.UserIDs = append(msg.UserIDs, id)
+	}
+	if msg.SessionID == "" && len(msg.UserIDs) == 0 && len(msg.Regions) == 0 && len(msg.Roles) == 0 {
+		return msg, status.Error(codes.InvalidArgument, "session_id, user_ids, regions or roles is required")
+	}
+	switch p := strings.ToLower(strings.TrimSpace(priority)); p {
+	case "":
+	case routing.PriorityLow, routing.PriorityNormal, routing.PriorityHigh, routing.PriorityCritical:
+		msg.Priority = p
+	default:
+		return msg, status.Error(codes.InvalidArgument, "priority must be low, normal, high or critical")
+	}
+	return msg, nil
+}
+
+// envelope проверяет JSON конверта события, переданного целиком, и возвращает его без изменений.
+func envelope(raw []byte) ([]byte, error) {
+	msg, err := routing.ParseMessage(raw)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid message")
+	}
+	switch e := strings.TrimSpace(msg.Event); {
+	case e == "":
+		return nil, status.Error(codes.InvalidArgument, "message.event is required")
+	case len(e) > maxEventTypeLength:
+		return nil, status.Errorf(codes.InvalidArgument, "message.event must be at most %d bytes", maxEventTypeLength)
+	}
+	if _, ok := msg.Session(); !ok && len(msg.DirectTargets()) == 0 && len(msg.Regions) == 0 && len(msg.Roles) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "message has no recipients")
+	}
+	return raw, nil
+}
+
+// marshalMessage добавляет payload и сериализует конверт.
+func marshalMessage(msg routing.Message, payload *structpb.Struct) ([]byte, error) {
+	if payload != nil {
+		data, err := json.Marshal(payload.AsMap())
+		if err != nil {
+			return nil, err
+		}
+		msg.Payload = data
+	}
+	return json.Marshal(msg)
+}
@@ -3,18 +3,42 @@ package grpc
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
 	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/internal/templates"
+	"github.com/psds-microservice/notification-service/internal/tracing"
 	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// ContactStore — хранилище контактов пользователей для внешних каналов.
+type ContactStore interface {
+	SetEmail(ctx context.Context, userID uuid.UUID, email string) error
+	SetLocale(ctx context.Context, userID uuid.UUID, locale string) error
+}
+
 // Deps — зависимости gRPC-сервера (D: зависимость от абстракций).
 type Deps struct {
-	Hub service.SessionBroadcaster
+	Hub         service.SessionBroadcaster
+	Contacts    ContactStore
+	Devices     DeviceStore
+	Preferences PreferenceStore
+	QuietHours  QuietHoursStore
+	Digests     DigestStore
+	Scheduled   ScheduledStore
+	Webhooks    WebhookStore
+	Templates   TemplateStore
+	History     HistoryStore
+	Router      MessageRouter
+	// AdminToken — bearer-токен ADMIN_TOKEN, без которого недоступен реестр webhook.
+	AdminToken string
 }
 
 // Server implements notification_service.NotificationServiceServer
@@ -32,7 +56,13 @@ func (s *Server) mapError(err error) error {
 	if err == nil {
 		return nil
 	}
-	log.Printf("grpc: error: %v", err)
+	if errors.Is(err, repository.ErrNotFound) {
+		return status.Error(codes.NotFound, "not found")
+	}
+	if errors.Is(err, repository.ErrNotPending) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	slog.Error("grpc: error", "error", err)
 	return status.Error(codes.Internal, err.Error())
 }
 
@@ -41,23 +71,73 @@ func (s *Server) NotifySession(ctx context.Context, req *notification_service.No
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid session id")
 	}
-	if req.GetEvent() == "" {
-		return nil, status.Error(codes.InvalidArgument, "event is required")
+	msg, err := newMessage(req.GetEvent(), sessionID.String(), nil, nil, nil, "")
+	if err != nil {
+		return nil, err
 	}
-
-	// Формируем сообщение для broadcast (payload — arbitrary JSON object via Struct)
-	var payload interface{}
-	if req.GetPayload() != nil {
-		payload = req.GetPayload().AsMap()
+	raw, err := marshalMessage(msg, req.GetPayload())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	// Событие проходит общий конвейер маршрутизации: настройки, история, шаблоны.
+	if s.Router != nil {
+		if err := s.Router.Route(ctx, raw); err != nil {
+			return nil, s.mapError(err)
+		}
+		return &notification_service.NotifySessionResponse{Ok: true}, nil
+	}
+	if s.Hub == nil {
+		return nil, status.Error(codes.Unavailable, "hub is not configured")
 	}
-	msg, err := json.Marshal(map[string]interface{}{
-		"event":   req.GetEvent(),
-		"payload": payload,
-	})
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		var frame map[string]interface{}
+		if err := json.Unmarshal(raw, &frame); err == nil {
+			frame["trace_id"] = traceID
+			if b, err := json.Marshal(frame); err == nil {
+				raw = b
+			}
+		}
+	}
+	s.Hub.BroadcastToSession(ctx, sessionID, raw)
+	return &notification_service.NotifySessionResponse{Ok: true}, nil
+}
+
+func (s *Server) SetUserContact(ctx context.Context, req *notification_service.SetUserContactRequest) (*notification_service.SetUserContactResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
 	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	email := strings.TrimSpace(req.GetEmail())
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Name != "" {
+			return nil, status.Error(codes.InvalidArgument, "invalid email")
+		}
+		email = addr.Address
+	}
+	if s.Contacts == nil {
+		return nil, status.Error(codes.Unavailable, "contact store is not configured")
+	}
+	if err := s.Contacts.SetEmail(ctx, userID, email); err != nil {
 		return nil, s.mapError(err)
 	}
+	return &notification_service.SetUserContactResponse{Ok: true}, nil
+}
 
-	s.Hub.BroadcastToSession(sessionID, msg)
-	return &notification_service.NotifySessionResponse{Ok: true}, nil
+func (s *Server) SetUserLocale(ctx context.Context, req *notification_service.SetUserLocaleRequest) (*notification_service.SetUserLocaleResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	locale := templates.NormalizeLocale(req.GetLocale())
+	if locale != "" && !validLocale(locale) {
+		return nil, status.Error(codes.InvalidArgument, "invalid locale")
+	}
+	if s.Contacts == nil {
+		return nil, status.Error(codes.Unavailable, "contact store is not configured")
+	}
+	if err := s.Contacts.SetLocale(ctx, userID, locale); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.SetUserLocaleResponse{Ok: true}, nil
 }
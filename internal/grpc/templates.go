@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/templates"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TemplateStore — шаблоны уведомлений.
+type TemplateStore interface {
+	List(ctx context.Context) ([]repository.Template, error)
+	Set(ctx context.Context, t repository.Template) (repository.Template, error)
+	Delete(ctx context.Context, eventType, locale string) error
+}
+
+// maxLocaleLength совпадает с notification_templates.locale.
+const maxLocaleLength = 16
+
+// maxTemplateLength — ограничение на размер шаблона заголовка или текста.
+const maxTemplateLength = 16 << 10
+
+// validLocale проверяет нормализованный тег BCP 47: "ru", "pt-br", "zh-hant-tw".
+func validLocale(locale string) bool {
+	if locale == "" || len(locale) > maxLocaleLength {
+		return false
+	}
+	for i, part := range strings.Split(locale, "-") {
+		if part == "" || len(part) > 8 || (i == 0 && (len(part) < 2 || len(part) > 3)) {
+			return false
+		}
+		for _, r := range part {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Server) ListTemplates(ctx context.Context, _ *notification_service.ListTemplatesRequest) (*notification_service.ListTemplatesResponse, error) {
+	if s.Templates == nil {
+		return nil, status.Error(codes.Unavailable, "template store is not configured")
+	}
+	list, err := s.Templates.List(ctx)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &notification_service.ListTemplatesResponse{}
+	for _, t := range list {
+		resp.Templates = append(resp.Templates, templateToProto(t))
+	}
+	return resp, nil
+}
+
+func (s *Server) SetTemplate(ctx context.Context, req *notification_service.SetTemplateRequest) (*notification_service.Template, error) {
+	eventType, locale, err := templateKey(req.GetEventType(), req.GetLocale())
+	if err != nil {
+		return nil, err
+	}
+	title, body := req.GetTitle(), req.GetBody()
+	if strings.TrimSpace(title) == "" || strings.TrimSpace(body) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and body are required")
+	}
+	if len(title) > maxTemplateLength || len(body) > maxTemplateLength {
+		return nil, status.Error(codes.InvalidArgument, "template is too large")
+	}
+	if err := templates.Compile(title, body); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid template: "+err.Error())
+	}
+	if s.Templates == nil {
+		return nil, status.Error(codes.Unavailable, "template store is not configured")
+	}
+	t, err := s.Templates.Set(ctx, repository.Template{EventType: eventType, Locale: locale, Title: title, Body: body})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return templateToProto(t), nil
+}
+
+func (s *Server) DeleteTemplate(ctx context.Context, req *notification_service.DeleteTemplateRequest) (*notification_service.DeleteTemplateResponse, error) {
+	eventType, locale, err := templateKey(req.GetEventType(), req.GetLocale())
+	if err != nil {
+		return nil, err
+	}
+	if s.Templates == nil {
+		return nil, status.Error(codes.Unavailable, "template store is not configured")
+	}
+	if err := s.Templates.Delete(ctx, eventType, locale); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.DeleteTemplateResponse{Ok: true}, nil
+}
+
+func templateKey(eventType, locale string) (string, string, error) {
+	eventType = strings.TrimSpace(eventType)
+	if eventType == "" || len(eventType) > maxEventTypeLength || strings.ContainsAny(eventType, " \t\r\n") {
+		return "", "", status.Error(codes.InvalidArgument, "invalid event type")
+	}
+	locale = templates.NormalizeLocale(locale)
+	if !validLocale(locale) {
+		return "", "", status.Error(codes.InvalidArgument, "invalid locale")
+	}
+	return eventType, locale, nil
+}
+
+func templateToProto(t repository.Template) *notification_service.Template {
+	out := &notification_service.Template{
+		EventType: t.EventType,
+		Locale:    t.Locale,
+		Title:     t.Title,
+		Body:      t.Body,
+	}
+	if !t.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(t.UpdatedAt)
+	}
+	return out
+}
@@ -0,0 +1,240 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/adminauth"
+	"github.com/psds-microservice/notification-service/internal/channel/webhook"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WebhookStore — реестр webhook-подписок и журнал их доставок.
+type WebhookStore interface {
+	Create(ctx context.Context, w repository.Webhook) (repository.Webhook, error)
+	Get(ctx context.Context, id uuid.UUID) (repository.Webhook, error)
+	List(ctx context.Context) ([]repository.Webhook, error)
+	Update(ctx context.Context, w repository.Webhook, active *bool) (repository.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, id uuid.UUID, after *repository.Cursor, limit int) ([]repository.WebhookDelivery, error)
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func (s *Server) CreateWebhook(ctx context.Context, req *notification_service.CreateWebhookRequest) (*notification_service.Webhook, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	u, events, err := validateWebhook(ctx, req.GetUrl(), req.GetEvents())
+	if err != nil {
+		return nil, err
+	}
+	secret := req.GetSecret()
+	if len(secret) > 128 {
+		return nil, status.Error(codes.InvalidArgument, "secret must be at most 128 characters")
+	}
+	if secret == "" {
+		if secret, err = webhook.GenerateSecret(); err != nil {
+			return nil, s.mapError(err)
+		}
+	}
+	created, err := s.Webhooks.Create(ctx, repository.Webhook{URL: u, Events: events, Secret: secret, Active: true})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return webhookToProto(created, true), nil
+}
+
+func (s *Server) ListWebhooks(ctx context.Context, _ *notification_service.ListWebhooksRequest) (*notification_service.ListWebhooksResponse, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	list, err := s.Webhooks.List(ctx)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &notification_service.ListWebhooksResponse{Webhooks: make([]*notification_service.Webhook, 0, len(list))}
+	for _, w := range list {
+		resp.Webhooks = append(resp.Webhooks, webhookToProto(w, false))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetWebhook(ctx context.Context, req *notification_service.GetWebhookRequest) (*notification_service.Webhook, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+	}
+	w, err := s.Webhooks.Get(ctx, id)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return webhookToProto(w, false), nil
+}
+
+func (s *Server) UpdateWebhook(ctx context.Context, req *notification_service.UpdateWebhookRequest) (*notification_service.Webhook, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+	}
+	u, events, err := validateWebhook(ctx, req.GetUrl(), req.GetEvents())
+	if err != nil {
+		return nil, err
+	}
+	w := repository.Webhook{ID: id, URL: u, Events: events}
+	if req.GetRotateSecret() {
+		if w.Secret, err = webhook.GenerateSecret(); err != nil {
+			return nil, s.mapError(err)
+		}
+	}
+	updated, err := s.Webhooks.Update(ctx, w, req.Active)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return webhookToProto(updated, req.GetRotateSecret()), nil
+}
+
+func (s *Server) DeleteWebhook(ctx context.Context, req *notification_service.DeleteWebhookRequest) (*notification_service.DeleteWebhookResponse, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+	}
+	if err := s.Webhooks.Delete(ctx, id); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &notification_service.DeleteWebhookResponse{Ok: true}, nil
+}
+
+func (s *Server) ListWebhookDeliveries(ctx context.Context, req *notification_service.ListWebhookDeliveriesRequest) (*notification_service.ListWebhookDeliveriesResponse, error) {
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+	}
+	after, err := repository.DecodeCursor(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	limit := pageSize(req.GetPageSize())
+	list, err := s.Webhooks.ListDeliveries(ctx, id, after, limit+1)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &notification_service.ListWebhookDeliveriesResponse{}
+	if len(list) > limit {
+		list = list[:limit]
+		last := list[len(list)-1]
+		resp.NextPageToken = repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, d := range list {
+		resp.Deliveries = append(resp.Deliveries, webhookDeliveryToProto(d))
+	}
+	return resp, nil
+}
+
+// authorizeWebhooks пускает к реестру webhook только с admin-токеном: подписка получает
+// payload всех подходящих событий, а журнал доставок — ответы её URL.
+func (s *Server) authorizeWebhooks(ctx context.Context) error {
+	if s.Webhooks == nil {
+		return status.Error(codes.Unavailable, "webhook registry is not configured")
+	}
+	if s.AdminToken == "" {
+		return status.Error(codes.Unavailable, "admin api is not configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if !adminauth.Valid(md.Get("authorization"), s.AdminToken) {
+		return status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return nil
+}
+
+// validateWebhook проверяет URL (абсолютный http/https на публичный адрес) и нормализует фильтры событий.
+func validateWebhook(ctx context.Context, rawURL string, filters []string) (string, []string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", nil, status.Error(codes.InvalidArgument, "url must be an absolute http(s) URL")
+	}
+	if err := webhook.CheckHost(ctx, u.Hostname()); err != nil {
+		return "", nil, status.Error(codes.InvalidArgument, "url must point to a public address")
+	}
+	events := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if f = strings.TrimSpace(f); f != "" {
+			events = append(events, f)
+		}
+	}
+	return u.String(), events, nil
+}
+
+func pageSize(requested int32) int {
+	switch {
+	case requested <= 0:
+		return defaultPageSize
+	case requested > maxPageSize:
+		return maxPageSize
+	}
+	return int(requested)
+}
+
+func webhookToProto(w repository.Webhook, withSecret bool) *notification_service.Webhook {
+	out := &notification_service.Webhook{
+		Id:        w.ID.String(),
+		Url:       w.URL,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: timestamppb.New(w.CreatedAt),
+		UpdatedAt: timestamppb.New(w.UpdatedAt),
+	}
+	if withSecret {
+		out.Secret = w.Secret
+	}
+	return out
+}
+
+func webhookDeliveryToProto(d repository.WebhookDelivery) *notification_service.WebhookDelivery {
+	out := &notification_service.WebhookDelivery{
+		Id:            d.ID.String(),
+		Event:         d.EventType,
+		Status:        d.Status,
+		Attempts:      int32(d.Attempts),
+		CreatedAt:     timestamppb.New(d.CreatedAt),
+		NextAttemptAt: timestamppb.New(d.NextAttemptAt),
+	}
+	if d.ResponseStatus != nil {
+		out.ResponseStatus = int32(*d.ResponseStatus)
+	}
+	if d.LastError != nil {
+		out.LastError = *d.LastError
+	}
+	if d.DeliveredAt != nil {
+		out.DeliveredAt = timestamppb.New(*d.DeliveredAt)
+	}
+	var payload map[string]interface{}
+	if json.Unmarshal(d.Payload, &payload) == nil {
+		out.Payload, _ = structpb.NewStruct(payload)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/psds-microservice/notification-service/internal/adminauth"
+)
+
+// RequireAdmin пропускает к next только запросы с заголовком Authorization: Bearer <token> (ADMIN_TOKEN);
+// та же проверка, что у gRPC admin API. Пустой token выключает обработчик: 503.
+func RequireAdmin(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case token == "":
+			writeAdminError(w, http.StatusServiceUnavailable, "admin api is not configured")
+		case !adminauth.Valid(r.Header.Values("Authorization"), token):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, http.StatusUnauthorized, "invalid admin token")
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func writeAdminError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 )
 
+// Health — liveness: процесс жив и обслуживает HTTP; зависимости проверяет /ready (internal/health).
 func Health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -14,8 +15,3 @@ func Health(w http.ResponseWriter, r *http.Request) {
 		"time":    time.Now().Unix(),
 	})
 }
-
-func Ready(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
-}
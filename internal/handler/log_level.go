@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/psds-microservice/notification-service/internal/logging"
+)
+
+// LogLevel — GET возвращает текущий уровень логирования, PUT {"level": "debug"} меняет его без перезапуска.
+func LogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid body"})
+			return
+		}
+		if err := logging.SetLevel(req.Level); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "level must be debug, info, warn or error"})
+			return
+		}
+		slog.InfoContext(r.Context(), "log level changed", "level", logging.Level.Level().String())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"level": strings.ToLower(logging.Level.Level().String())})
+}
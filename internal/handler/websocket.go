@@ -1,38 +1,83 @@
 package handler
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/internal/metrics"
 	"github.com/psds-microservice/notification-service/internal/service"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  4096,
-	WriteBufferSize: 4096,
-	CheckOrigin:     func(r *http.Request) bool { return true },
+// WebSocketConfig — параметры upgrade и ограничения per-connection настроек.
+type WebSocketConfig struct {
+	ReadBufferSize   int
+	WriteBufferSize  int
+	Compression      bool
+	CompressionLevel int
+	BatchMaxWindow   time.Duration
+	BatchMaxMessages int
+}
+
+// PendingStore — кадры, не доставленные пользователю до остановки реплики (notification_pending).
+type PendingStore interface {
+	Take(ctx context.Context, userID uuid.UUID, limit int) ([][]byte, error)
+	Save(ctx context.Context, userID uuid.UUID, frames [][]byte) error
 }
 
 type WebSocketHandler struct {
-	Hub *service.NotifyHub
+	Hub      *service.NotifyHub
+	cfg      WebSocketConfig
+	pending  PendingStore
+	upgrader websocket.Upgrader
 }
 
-func NewWebSocketHandler(hub *service.NotifyHub) *WebSocketHandler {
-	return &WebSocketHandler{Hub: hub}
+// NewWebSocketHandler создаёт обработчик; pending (может быть nil) — кадры для отправки при подключении.
+func NewWebSocketHandler(hub *service.NotifyHub, pending PendingStore, cfg WebSocketConfig) *WebSocketHandler {
+	if cfg.ReadBufferSize <= 0 {
+		cfg.ReadBufferSize = 4096
+	}
+	if cfg.WriteBufferSize <= 0 {
+		cfg.WriteBufferSize = 4096
+	}
+	return &WebSocketHandler{
+		Hub:     hub,
+		cfg:     cfg,
+		pending: pending,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:    cfg.ReadBufferSize,
+			WriteBufferSize:   cfg.WriteBufferSize,
+			EnableCompression: cfg.Compression,
+			Subprotocols:      service.Subprotocols,
+			CheckOrigin:       func(r *http.Request) bool { return true },
+		},
+	}
 }
 
 func (h *WebSocketHandler) ServeWS(c *gin.Context) {
 	userIDStr := c.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
+		metrics.WSUpgradeFailures.WithLabelValues("invalid_user_id").Inc()
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
 		return
 	}
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if h.Hub.Draining() {
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service restart"})
+		return
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
+		metrics.WSUpgradeFailures.WithLabelValues("upgrade").Inc()
+		slog.DebugContext(c.Request.Context(), "ws: upgrade failed", "user_id", userID, "error", err)
 		return
 	}
 	// Дополнительные атрибуты клиента (для agent routing) можно передавать в query:
@@ -47,14 +92,94 @@ func (h *WebSocketHandler) ServeWS(c *gin.Context) {
 		}
 	}
 
+	// Сжатие и coalescing настраиваются per-connection:
+	//   ?compress=false — не сжимать исходящие кадры, даже если permessage-deflate согласован;
+	//   ?batch_ms=50 — отправлять накопленные за 50 мс сообщения одним кадром (не больше WS_BATCH_MAX_WINDOW).
+	if h.cfg.Compression {
+		if compress, err := strconv.ParseBool(c.Query("compress")); err == nil && !compress {
+			conn.EnableWriteCompression(false)
+		} else {
+			_ = conn.SetCompressionLevel(h.cfg.CompressionLevel)
+		}
+	}
+	var batchWindow time.Duration
+	if ms, err := strconv.Atoi(c.Query("batch_ms")); err == nil && ms > 0 {
+		batchWindow = time.Duration(ms) * time.Millisecond
+		if batchWindow > h.cfg.BatchMaxWindow {
+			batchWindow = h.cfg.BatchMaxWindow
+		}
+	}
+
+	// Язык шаблонов уведомлений: ?locale=ru, иначе первый язык из Accept-Language.
+	locale := strings.TrimSpace(c.Query("locale"))
+	if locale == "" {
+		locale = firstLanguage(c.GetHeader("Accept-Language"))
+	}
+
 	meta := service.ClientMetadata{
-		Region: region,
-		Roles:  roles,
+		Region:           region,
+		Roles:            roles,
+		Locale:           locale,
+		Subprotocol:      conn.Subprotocol(),
+		BatchWindow:      batchWindow,
+		BatchMaxMessages: h.cfg.BatchMaxMessages,
+		RemoteAddr:       c.ClientIP(),
 	}
 
-	client := h.Hub.Register(userID, conn, meta)
-	defer h.Hub.Unregister(userID)
+	client, err := h.Hub.Register(userID, conn, meta)
+	if err != nil {
+		// Реплика останавливается между проверкой Draining и upgrade.
+		msg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "service restart")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	defer h.Hub.UnregisterConn(client)
+	ctx := logging.With(c.Request.Context(), "user_id", userID, "conn_id", client.ID)
+	slog.DebugContext(ctx, "ws: connected", "region", region, "roles", roles, "subprotocol", meta.Subprotocol, "locale", locale)
+	defer slog.DebugContext(ctx, "ws: disconnected")
 
 	go client.WritePump()
+	h.replayPending(ctx, client)
+	h.Hub.NotifyConnected(ctx, userID)
 	client.ReadPump(h.Hub, userID)
 }
+
+// replayPending отправляет кадры, не доставленные пользователю до остановки предыдущей реплики.
+// Кадры кладутся в очередь именно этого подключения; те, что не поместились (очередь уже заняли
+// новые сообщения) или не застали подключение, сохраняются обратно до следующего подключения.
+func (h *WebSocketHandler) replayPending(ctx context.Context, client *service.ClientConn) {
+	if h.pending == nil {
+		return
+	}
+	frames, err := h.pending.Take(ctx, client.UserID, cap(client.Send))
+	if err != nil {
+		slog.ErrorContext(ctx, "ws: pending messages", "error", err)
+		return
+	}
+	for i, f := range frames {
+		if h.Hub.SendToConn(client, f) {
+			continue
+		}
+		rest := frames[i:]
+		if err := h.pending.Save(context.WithoutCancel(ctx), client.UserID, rest); err != nil {
+			slog.ErrorContext(ctx, "ws: pending messages lost", "count", len(rest), "error", err)
+		}
+		frames = frames[:i]
+		break
+	}
+	if len(frames) > 0 {
+		slog.DebugContext(ctx, "ws: pending messages replayed", "count", len(frames))
+	}
+}
+
+// firstLanguage возвращает первый язык из Accept-Language ("ru-RU,ru;q=0.9,en;q=0.8" → "ru-RU").
+func firstLanguage(header string) string {
+	tag, _, _ := strings.Cut(header, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" || len(tag) > 16 {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,105 @@
+// Package health — readiness: проверки зависимостей с разбивкой по каждой и состояние drain при остановке.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check — проверка одной зависимости. Critical: при её отказе под не готов (503),
+// иначе — degraded (200).
+type Check struct {
+	Name     string
+	Critical bool
+	Fn       func(ctx context.Context) error
+}
+
+// Result — результат проверки зависимости.
+type Result struct {
+	Status    string `json:"status"` // up, down
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report — ответ /ready.
+type Report struct {
+	Status string            `json:"status"` // ready, degraded, not_ready, draining
+	Checks map[string]Result `json:"checks"`
+}
+
+// Readiness выполняет проверки параллельно с таймаутом на каждую.
+type Readiness struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewReadiness(timeout time.Duration, checks ...Check) *Readiness {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Readiness{checks: checks, timeout: timeout}
+}
+
+// SetDraining переводит сервис в режим остановки: /ready отвечает 503, чтобы балансировщик
+// перестал направлять новые подключения.
+func (r *Readiness) SetDraining() { r.draining.Store(true) }
+
+// Draining сообщает, идёт ли остановка.
+func (r *Readiness) Draining() bool { return r.draining.Load() }
+
+// Check выполняет проверки и возвращает отчёт.
+func (r *Readiness) Check(ctx context.Context) Report {
+	results := make(map[string]Result, len(r.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+			start := time.Now()
+			err := c.Fn(cctx)
+			res := Result{Status: "up", Critical: c.Critical, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Status, res.Error = "down", err.Error()
+			}
+			mu.Lock()
+			results[c.Name] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	rep := Report{Status: "ready", Checks: results}
+	for _, res := range results {
+		if res.Status == "up" {
+			continue
+		}
+		if res.Critical {
+			rep.Status = "not_ready"
+			break
+		}
+		rep.Status = "degraded"
+	}
+	if r.Draining() {
+		rep.Status = "draining"
+	}
+	return rep
+}
+
+// ServeHTTP отдаёт отчёт: 200 для ready/degraded, 503 для not_ready/draining.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rep := r.Check(req.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if rep.Status == "not_ready" || rep.Status == "draining" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(rep)
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// RedisPing возвращает проверку Redis по REDIS_URL (redis:// или rediss:// для TLS,
+// [user:password@]host:port/db): AUTH при наличии пароля и PING без клиентской библиотеки.
+func RedisPing(rawURL string) (func(ctx context.Context) error, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, errors.New("health: invalid REDIS_URL")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	var auth []string
+	if pass, ok := u.User.Password(); ok {
+		if name := u.User.Username(); name != "" {
+			auth = []string{"AUTH", name, pass}
+		} else {
+			auth = []string{"AUTH", pass}
+		}
+	}
+	dial := (&net.Dialer{}).DialContext
+	if u.Scheme == "rediss" {
+		d := &tls.Dialer{Config: &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}}
+		dial = d.DialContext
+	}
+	return func(ctx context.Context) error {
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		rd := bufio.NewReader(conn)
+		if auth != nil {
+			if err := redisCommand(conn, rd, auth...); err != nil {
+				return fmt.Errorf("auth: %w", err)
+			}
+		}
+		return redisCommand(conn, rd, "PING")
+	}, nil
+}
+
+func redisCommand(conn net.Conn, rd *bufio.Reader, args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(line, "-") {
+		return errors.New(strings.TrimSpace(line[1:]))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/internal/service"
+)
+
+// defaultLimit — размер страницы запроса history по WebSocket, если limit не задан.
+const defaultLimit = 50
+
+// ackTimeout — сколько ждать отметки seen по ack клиента.
+const ackTimeout = 5 * time.Second
+
+// History — история и входящие уведомления пользователей поверх notification_events: реализует
+// routing.HistoryRecorder, обработчики WebSocket (history, ack) и отметки read/seen/dismissed.
+// Когда число непрочитанных у подключённого пользователя меняется, ему отправляется unread_count.
+type History struct {
+	repo *repository.HistoryRepository
+	hub  *service.NotifyHub
+}
+
+func New(repo *repository.HistoryRepository, hub *service.NotifyHub) *History {
+	return &History{repo: repo, hub: hub}
+}
+
+// Record сохраняет событие в историю получателей.
+func (h *History) Record(ctx context.Context, notificationID uuid.UUID, msg routing.Message, userIDs []uuid.UUID) error {
+	var sessionID uuid.NullUUID
+	if sid, ok := msg.Session(); ok {
+		sessionID = uuid.NullUUID{UUID: sid, Valid: true}
+	}
+	if err := h.repo.Record(ctx, notificationID, sessionID, msg.Event, msg.Payload, userIDs); err != nil {
+		return err
+	}
+	h.pushUnread(ctx, userIDs)
+	return nil
+}
+
+// List возвращает историю по фильтрам.
+func (h *History) List(ctx context.Context, q repository.HistoryQuery) ([]repository.Notification, error) {
+	return h.repo.List(ctx, q)
+}
+
+// Mark выставляет состояние уведомлениям пользователя; возвращает, скольким оно изменилось.
+func (h *History) Mark(ctx context.Context, userID uuid.UUID, state string, notificationIDs []uuid.UUID) (int64, error) {
+	n, err := h.repo.Mark(ctx, userID, state, notificationIDs)
+	if err == nil && n > 0 && state != repository.StateSeen {
+		h.pushUnread(ctx, []uuid.UUID{userID})
+	}
+	return n, err
+}
+
+// MarkBefore — Mark для всех уведомлений пользователя, созданных раньше before.
+func (h *History) MarkBefore(ctx context.Context, userID uuid.UUID, state string, before time.Time) (int64, error) {
+	n, err := h.repo.MarkBefore(ctx, userID, state, before)
+	if err == nil && n > 0 && state != repository.StateSeen {
+		h.pushUnread(ctx, []uuid.UUID{userID})
+	}
+	return n, err
+}
+
+// UnreadCount возвращает число непрочитанных уведомлений пользователя.
+func (h *History) UnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	counts, err := h.repo.UnreadCounts(ctx, []uuid.UUID{userID})
+	return counts[userID], err
+}
+
+// HandleAck — service.AckHandler: клиент подтвердил получение, уведомление отмечается просмотренным.
+func (h *History) HandleAck(userID, notificationID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), ackTimeout)
+	defer cancel()
+	if _, err := h.repo.Mark(ctx, userID, repository.StateSeen, []uuid.UUID{notificationID}); err != nil {
+		slog.ErrorContext(ctx, "history: ack", "user_id", userID, "notification_id", notificationID, "error", err)
+	}
+}
+
+// HandleConnect — service.ConnectHandler: при подключении клиент получает текущее число непрочитанных.
+func (h *History) HandleConnect(ctx context.Context, userID uuid.UUID) {
+	h.pushUnread(ctx, []uuid.UUID{userID})
+}
+
+// pushUnread отправляет unread_count подключённым к этой реплике пользователям из userIDs.
+func (h *History) pushUnread(ctx context.Context, userIDs []uuid.UUID) {
+	online := make([]uuid.UUID, 0, len(userIDs))
+	for _, uid := range userIDs {
+		if h.hub.IsOnline(uid) {
+			online = append(online, uid)
+		}
+	}
+	if len(online) == 0 {
+		return
+	}
+	counts, err := h.repo.UnreadCounts(ctx, online)
+	if err != nil {
+		slog.ErrorContext(ctx, "history: unread counts", "error", err)
+		return
+	}
+	for _, uid := range online {
+		h.hub.SendUnreadCount(ctx, uid, counts[uid])
+	}
+}
+
+// Item — уведомление в ответе на history; поля совпадают с кадром события.
+type Item struct {
+	NotificationID string          `json:"notification_id"`
+	Event          string          `json:"event"`
+	SessionID      string          `json:"session_id,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	SeenAt         *time.Time      `json:"seen_at,omitempty"`
+	ReadAt         *time.Time      `json:"read_at,omitempty"`
+	DismissedAt    *time.Time      `json:"dismissed_at,omitempty"`
+}
+
+// Page — data ответа на history: уведомления от новых к старым и page_token следующей страницы.
+type Page struct {
+	Notifications []Item `json:"notifications"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// HandleWS — service.HistoryHandler: история пользователя для догрузки при подключении.
+func (h *History) HandleWS(ctx context.Context, userID uuid.UUID, q service.HistoryQuery) (interface{}, error) {
+	after, err := repository.DecodeCursor(q.PageToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidRequest, err)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	query := repository.HistoryQuery{
+		UserID:           uuid.NullUUID{UUID: userID, Valid: true},
+		EventTypes:       q.EventTypes,
+		Since:            q.Since,
+		Until:            q.Until,
+		UnreadOnly:       q.UnreadOnly,
+		ExcludeDismissed: q.ExcludeDismissed,
+		After:            after,
+		Limit:            limit + 1,
+	}
+	if q.SessionID != uuid.Nil {
+		query.SessionID = uuid.NullUUID{UUID: q.SessionID, Valid: true}
+	}
+	list, err := h.repo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	page := Page{Notifications: make([]Item, 0, len(list))}
+	if len(list) > limit {
+		list = list[:limit]
+		last := list[len(list)-1]
+		page.NextPageToken = repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, n := range list {
+		item := Item{
+			NotificationID: n.NotificationID.String(),
+			Event:          n.EventType,
+			Payload:        n.Payload,
+			CreatedAt:      n.CreatedAt,
+			SeenAt:         n.SeenAt,
+			ReadAt:         n.ReadAt,
+			DismissedAt:    n.DismissedAt,
+		}
+		if n.SessionID.Valid {
+			item.SessionID = n.SessionID.UUID.String()
+		}
+		page.Notifications = append(page.Notifications, item)
+	}
+	return page, nil
+}
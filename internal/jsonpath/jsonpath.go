@@ -0,0 +1,158 @@
+// Package jsonpath — минимальное подмножество jq для отладочных команд: пути (.a.b, .items[0], ."x.y")
+// и условия (.event == "x", .payload.n != 1, .payload.flag).
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Path — путь к значению в разобранном JSON; пустой путь (".") — значение целиком.
+type Path []step
+
+type step struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// Parse разбирает путь вида .a.b[0]."c.d".
+func Parse(expr string) (Path, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "." {
+		return Path{}, nil
+	}
+	if !strings.HasPrefix(expr, ".") && !strings.HasPrefix(expr, "[") {
+		return nil, fmt.Errorf("path %q must start with '.'", expr)
+	}
+	var p Path
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			i++
+			if i < len(expr) && expr[i] == '[' {
+				continue
+			}
+			if i < len(expr) && expr[i] == '"' {
+				end := strings.IndexByte(expr[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("path %q: unterminated quoted key", expr)
+				}
+				p = append(p, step{key: expr[i+1 : i+1+end]})
+				i += end + 2
+				continue
+			}
+			j := i
+			for j < len(expr) && expr[j] != '.' && expr[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("path %q: empty key", expr)
+			}
+			p = append(p, step{key: expr[i:j]})
+			i = j
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unterminated index", expr)
+			}
+			n, err := strconv.Atoi(expr[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("path %q: invalid index: %w", expr, err)
+			}
+			p = append(p, step{index: n, isIdx: true})
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", expr, expr[i])
+		}
+	}
+	return p, nil
+}
+
+// Get возвращает значение по пути; false — пути нет. Отрицательный индекс считается с конца.
+func (p Path) Get(v interface{}) (interface{}, bool) {
+	for _, s := range p {
+		if s.isIdx {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			i := s.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			v = arr[i]
+			continue
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[s.key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Condition — условие над значением: путь, оператор (==, != или пусто — «значение есть и не false/null»).
+type Condition struct {
+	path  Path
+	op    string
+	value interface{}
+}
+
+// ParseCondition разбирает ".event == \"x\"", ".payload.n != 1" или ".payload.flag".
+// Правая часть — JSON-литерал; строку можно не брать в кавычки.
+func ParseCondition(expr string) (Condition, error) {
+	if i, op := findOp(expr); op != "" {
+		p, err := Parse(expr[:i])
+		if err != nil {
+			return Condition{}, err
+		}
+		right := strings.TrimSpace(expr[i+len(op):])
+		var value interface{}
+		if err := json.Unmarshal([]byte(right), &value); err != nil {
+			value = right
+		}
+		return Condition{path: p, op: op, value: value}, nil
+	}
+	p, err := Parse(expr)
+	if err != nil {
+		return Condition{}, err
+	}
+	return Condition{path: p}, nil
+}
+
+// findOp ищет первый оператор == или != вне ключей в кавычках: в правой части он может встретиться снова.
+func findOp(expr string) (int, string) {
+	quoted := false
+	for i := 0; i+1 < len(expr); i++ {
+		switch {
+		case expr[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case expr[i:i+2] == "==", expr[i:i+2] == "!=":
+			return i, expr[i : i+2]
+		}
+	}
+	return -1, ""
+}
+
+// Match проверяет условие на разобранном JSON.
+func (c Condition) Match(v interface{}) bool {
+	got, ok := c.path.Get(v)
+	switch c.op {
+	case "==":
+		return ok && reflect.DeepEqual(got, c.value)
+	case "!=":
+		return !ok || !reflect.DeepEqual(got, c.value)
+	}
+	return ok && got != nil && got != false
+}
@@ -0,0 +1,119 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Path
+		wantErr bool
+	}{
+		{".", Path{}, false},
+		{".event", Path{{key: "event"}}, false},
+		{" .payload.user_id ", Path{{key: "payload"}, {key: "user_id"}}, false},
+		{".items[0]", Path{{key: "items"}, {index: 0, isIdx: true}}, false},
+		{".items.[-1]", Path{{key: "items"}, {index: -1, isIdx: true}}, false},
+		{"[2].id", Path{{index: 2, isIdx: true}, {key: "id"}}, false},
+		{`."x.y".z`, Path{{key: "x.y"}, {key: "z"}}, false},
+		{"event", nil, true},
+		{".a..b", nil, true},
+		{`."x`, nil, true},
+		{".items[0", nil, true},
+		{".items[x]", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathGet(t *testing.T) {
+	doc := decode(t, `{"event":"x","items":[{"id":1},{"id":2}],"x.y":true}`)
+	tests := []struct {
+		expr string
+		want interface{}
+		ok   bool
+	}{
+		{".event", "x", true},
+		{".items[1].id", 2.0, true},
+		{".items[-1].id", 2.0, true},
+		{".items[2]", nil, false},
+		{".items[-3]", nil, false},
+		{`."x.y"`, true, true},
+		{".event.name", nil, false},
+		{".missing", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := p.Get(doc)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		expr    string
+		doc     string
+		want    bool
+		wantErr bool
+	}{
+		{`.event == "session.created"`, `{"event":"session.created"}`, true, false},
+		{`.event == session.created`, `{"event":"session.created"}`, true, false},
+		{`.event == "session.created"`, `{"event":"session.closed"}`, false, false},
+		{`.payload.n != 1`, `{"payload":{"n":2}}`, true, false},
+		{`.payload.n != 1`, `{"payload":{"n":1}}`, false, false},
+		{`.payload.n != 1`, `{"payload":{}}`, true, false},
+		{`.payload.n == 1`, `{"payload":{}}`, false, false},
+		{`.event != "a==b"`, `{"event":"a==b"}`, false, false},
+		{`.event != "a==b"`, `{"event":"a"}`, true, false},
+		{`.event == "a!=b"`, `{"event":"a!=b"}`, true, false},
+		{`."a==b" == 1`, `{"a==b":1}`, true, false},
+		{`.payload.flag`, `{"payload":{"flag":true}}`, true, false},
+		{`.payload.flag`, `{"payload":{"flag":false}}`, false, false},
+		{`.payload.flag`, `{"payload":{"flag":null}}`, false, false},
+		{`.payload.flag`, `{"payload":{}}`, false, false},
+		{`.payload.tags == ["a","b"]`, `{"payload":{"tags":["a","b"]}}`, true, false},
+		{`event == "x"`, ``, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCondition(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCondition() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.Match(decode(t, tt.doc)); got != tt.want {
+				t.Errorf("Match(%s) = %v, want %v", tt.doc, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,40 +2,26 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"log"
-	"strings"
+	"log/slog"
+	"strconv"
 	"time"
 
-	"github.com/google/uuid"
-	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/internal/metrics"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/internal/tracing"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func RunConsumer(ctx context.Context, brokers []string, groupID string, topics []string, hub *service.NotifyHub) {
+// RunConsumer читает топики и маршрутизирует сообщения; результат чтения отражается в health (может быть nil).
+func RunConsumer(ctx context.Context, brokers []string, groupID string, topics []string, router *routing.Router, health *Health) {
 	if len(brokers) == 0 || len(topics) == 0 {
 		return
 	}
 
-	type routingMessage struct {
-		// Общий конверт события, если продюсер его использует.
-		Event   string          `json:"event"`
-		Payload json.RawMessage `json:"payload,omitempty"`
-
-		// Базовые поля совместимы с предыдущей версией.
-		SessionID string   `json:"session_id,omitempty"`
-		UserID    string   `json:"user_id,omitempty"`
-		UserIDs   []string `json:"user_ids,omitempty"`
-
-		// Для agent routing по операторам/агентам.
-		OperatorID  string   `json:"operator_id,omitempty"`
-		OperatorIDs []string `json:"operator_ids,omitempty"`
-
-		// Для маршрутизации по регионам и ролям (если клиенты подключаются с этими атрибутами).
-		Regions []string `json:"regions,omitempty"`
-		Roles   []string `json:"roles,omitempty"`
-	}
-
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        brokers,
 		GroupID:        groupID,
@@ -59,56 +45,70 @@ func RunConsumer(ctx context.Context, brokers []string, groupID string, topics [
 			if ctx.Err() != nil {
 				return
 			}
-			log.Printf("kafka read: %v", err)
+			metrics.KafkaReadErrors.Inc()
+			health.record(err)
+			slog.ErrorContext(ctx, "kafka: read", "error", err)
 			continue
 		}
+		health.record(nil)
+		metrics.KafkaConsumerLag.WithLabelValues(msg.Topic, strconv.Itoa(msg.Partition)).Set(float64(msg.HighWaterMark - msg.Offset - 1))
 
-		var rm routingMessage
-		if err := json.Unmarshal(msg.Value, &rm); err != nil {
-			log.Printf("kafka: cannot unmarshal message: %v", err)
+		if err := route(ctx, router, msg); err != nil {
+			metrics.KafkaMessages.WithLabelValues(msg.Topic, "failed").Inc()
+			slog.ErrorContext(ctx, "kafka: route", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
 			continue
 		}
+		metrics.KafkaMessages.WithLabelValues(msg.Topic, "ok").Inc()
+	}
+}
 
-		// Для WebSocket-клиента пересылаем оригинальное тело сообщения как есть.
-		out := msg.Value
-
-		// 1. Рассылка по session_id (как раньше).
-		if rm.SessionID != "" {
-			if sid, err := uuid.Parse(strings.TrimSpace(rm.SessionID)); err == nil {
-				hub.BroadcastToSession(sid, out)
-			}
-		}
+// route маршрутизирует сообщение в спане, продолжающем трассировку из заголовков Kafka (traceparent).
+func route(ctx context.Context, router *routing.Router, msg kafka.Message) error {
+	ctx = tracing.Propagator.Extract(ctx, headerCarrier{headers: &msg.Headers})
+	ctx = logging.With(ctx, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	ctx, span := tracing.Tracer.Start(ctx, msg.Topic+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.destination.partition", msg.Partition),
+			attribute.Int64("messaging.kafka.message.offset", msg.Offset),
+		))
+	defer span.End()
+	err := router.Route(ctx, msg.Value)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
 
-		// 2. Прямые получатели по user_id / user_ids / operator_id / operator_ids.
-		var directTargets []uuid.UUID
-		appendID := func(idStr string) {
-			if idStr == "" {
-				return
-			}
-			if uid, err := uuid.Parse(strings.TrimSpace(idStr)); err == nil {
-				directTargets = append(directTargets, uid)
-			}
-		}
+// headerCarrier — propagation.TextMapCarrier поверх заголовков сообщения Kafka.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
 
-		appendID(rm.UserID)
-		for _, id := range rm.UserIDs {
-			appendID(id)
-		}
-		appendID(rm.OperatorID)
-		for _, id := range rm.OperatorIDs {
-			appendID(id)
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
 		}
+	}
+	return ""
+}
 
-		if len(directTargets) > 0 {
-			hub.BroadcastToUsers(directTargets, out)
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
 		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
 
-		// 3. Маршрутизация по регионам и ролям (если клиенты передают эти атрибуты при подключении).
-		if len(rm.Regions) > 0 {
-			hub.BroadcastToRegions(rm.Regions, out)
-		}
-		if len(rm.Roles) > 0 {
-			hub.BroadcastToRoles(rm.Roles, out)
-		}
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
 	}
+	return keys
 }
@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// readErrorWindow — ошибка чтения учитывается в readiness, если после неё за это время не было успешного чтения.
+const readErrorWindow = 30 * time.Second
+
+// Health — состояние консьюмера для readiness: последняя ошибка чтения и доступность брокеров.
+type Health struct {
+	brokers []string
+	enabled bool
+
+	mu        sync.Mutex
+	lastOK    time.Time
+	lastErr   error
+	lastErrAt time.Time
+}
+
+// NewHealth создаёт состояние консьюмера; без брокеров или топиков консьюмер выключен и проверка проходит.
+func NewHealth(brokers, topics []string) *Health {
+	return &Health{brokers: brokers, enabled: len(brokers) > 0 && len(topics) > 0}
+}
+
+func (h *Health) record(err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.lastErr, h.lastErrAt = err, time.Now()
+		return
+	}
+	h.lastOK = time.Now()
+}
+
+// Check возвращает ошибку, если последнее чтение завершилось ошибкой (не раньше readErrorWindow)
+// или ни один брокер не принимает подключение.
+func (h *Health) Check(ctx context.Context) error {
+	if !h.enabled {
+		return nil
+	}
+	h.mu.Lock()
+	lastErr, lastErrAt, lastOK := h.lastErr, h.lastErrAt, h.lastOK
+	h.mu.Unlock()
+	if lastErr != nil && lastErrAt.After(lastOK) && time.Since(lastErrAt) < readErrorWindow {
+		return fmt.Errorf("read: %w", lastErr)
+	}
+	var errs []error
+	for _, broker := range h.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/tracing"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publish отправляет одно сообщение в топик; контекст трассировки передаётся в заголовках (traceparent),
+// как их читает RunConsumer.
+func Publish(ctx context.Context, brokers []string, topic string, key, value []byte) error {
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		WriteTimeout: 10 * time.Second,
+	}
+	defer w.Close()
+	msg := kafka.Message{Key: key, Value: value}
+	tracing.Propagator.Inject(ctx, headerCarrier{headers: &msg.Headers})
+	return w.WriteMessages(ctx, msg)
+}
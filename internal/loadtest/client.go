@@ -0,0 +1,183 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// subscribeTimeout — сколько ждать ответа на subscribe.
+const subscribeTimeout = 10 * time.Second
+
+// client — симулированный WebSocket-клиент. Поля статистики пишет только горутина чтения;
+// читать их можно после закрытия done.
+type client struct {
+	userID  uuid.UUID
+	region  string
+	role    string
+	session uuid.UUID
+
+	conn    *websocket.Conn
+	ready   chan error
+	done    chan struct{}
+	closing atomic.Bool
+
+	seen         map[int64]struct{}
+	latencies    []time.Duration
+	duplicates   int
+	disconnected bool
+}
+
+func newClient(region, role string, session uuid.UUID) *client {
+	return &client{
+		userID:  uuid.New(),
+		region:  region,
+		role:    role,
+		session: session,
+		ready:   make(chan error, 1),
+		done:    make(chan struct{}),
+		seen:    make(map[int64]struct{}),
+	}
+}
+
+// connect подключается к /ws/notify/:user_id и, если у клиента есть сессия, подписывается на неё.
+func (c *client) connect(ctx context.Context, cfg Config, dialer *websocket.Dialer, t *tracker) error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/ws/notify/" + c.userID.String()
+	q := u.Query()
+	if c.region != "" {
+		q.Set("region", c.region)
+	}
+	if c.role != "" {
+		q.Set("roles", c.role)
+	}
+	if cfg.BatchMS > 0 {
+		q.Set("batch_ms", strconv.Itoa(cfg.BatchMS))
+	}
+	u.RawQuery = q.Encode()
+	header := http.Header{}
+	if cfg.Token != "" {
+		header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("connect: %s", resp.Status)
+		}
+		return err
+	}
+	c.conn = conn
+	go c.read(t)
+	if c.session == uuid.Nil {
+		return nil
+	}
+	req := map[string]string{"type": "subscribe", "session_id": c.session.String(), "request_id": "subscribe"}
+	if err := conn.WriteJSON(req); err != nil {
+		return err
+	}
+	select {
+	case err := <-c.ready:
+		return err
+	case <-c.done:
+		return errors.New("closed before subscribe")
+	case <-time.After(subscribeTimeout):
+		return errors.New("subscribe timeout")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// frame — поля входящих сообщений, нужные тесту: ответ на subscribe или событие рассылки.
+type frame struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id"`
+	Event     string `json:"event"`
+	Payload   struct {
+		Loadtest struct {
+			Seq int64 `json:"seq"`
+		} `json:"loadtest"`
+	} `json:"payload"`
+}
+
+func (c *client) read(t *tracker) {
+	defer close(c.done)
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.disconnected = !c.closing.Load()
+			return
+		}
+		received := time.Now()
+		// Кадр с coalescing (?batch_ms) — JSON-массив сообщений.
+		var msgs []json.RawMessage
+		if d := bytes.TrimSpace(data); len(d) > 0 && d[0] == '[' {
+			if json.Unmarshal(d, &msgs) != nil {
+				continue
+			}
+		} else {
+			msgs = []json.RawMessage{data}
+		}
+		for _, m := range msgs {
+			var f frame
+			if json.Unmarshal(m, &f) != nil {
+				continue
+			}
+			switch {
+			case f.RequestID == "subscribe":
+				var err error
+				if f.Type != "ok" {
+					err = fmt.Errorf("subscribe: %s", m)
+				}
+				select {
+				case c.ready <- err:
+				default:
+				}
+			case f.Event == Event:
+				c.record(f.Payload.Loadtest.Seq, received, t)
+			}
+		}
+	}
+}
+
+func (c *client) record(seq int64, at time.Time, t *tracker) {
+	sent, ok := t.sentAt(seq)
+	if !ok {
+		return
+	}
+	if _, dup := c.seen[seq]; dup {
+		c.duplicates++
+		return
+	}
+	c.seen[seq] = struct{}{}
+	c.latencies = append(c.latencies, at.Sub(sent))
+}
+
+// close закрывает подключение close-кадром и ждёт завершения чтения.
+func (c *client) close() {
+	if c.conn == nil {
+		return
+	}
+	c.closing.Store(true)
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	select {
+	case <-c.done:
+	case <-time.After(time.Second):
+	}
+	c.conn.Close()
+	<-c.done
+}
@@ -0,0 +1,409 @@
+// Package loadtest — нагрузочный тест NotifyHub и /ws/notify: N симулированных WebSocket-клиентов
+// с регионами, ролями и подписками на сессии, рассылки через gRPC SendNotification с заданной частотой,
+// задержка доставки, потери и память сервиса (по /metrics).
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Event — тип события рассылок теста; кадры других событий клиенты пропускают. Маршрутизатор
+// доставляет его только по WebSocket: история и внешние каналы не затрагиваются.
+const Event = routing.EventLoadtest
+
+// Адресаты рассылок: по очереди подписчики сессии, регион, роль или один клиент.
+const (
+	TargetSession = service.TargetSession
+	TargetRegion  = service.TargetRegion
+	TargetRole    = service.TargetRole
+	TargetUser    = service.TargetUser
+)
+
+// maxInflight — сколько вызовов SendNotification может выполняться одновременно.
+const maxInflight = 256
+
+// MaxBroadcasts — ограничение rate × duration: время отправки хранится для каждой рассылки.
+const MaxBroadcasts = 1_000_000
+
+// sendTimeout — таймаут одного вызова SendNotification.
+const sendTimeout = 10 * time.Second
+
+// Config — параметры теста.
+type Config struct {
+	// URL — базовый адрес WebSocket сервиса (ws://host:port).
+	URL string
+	// MetricsURL — адрес /metrics сервиса; пусто — память сервиса не снимается.
+	MetricsURL string
+	Token      string
+	Clients    int
+	// ConnectRate — подключений в секунду.
+	ConnectRate int
+	// Клиенту i достаются Regions[i % len], Roles[i % len] и i % Sessions-я сессия.
+	Regions  []string
+	Roles    []string
+	Sessions int
+	// Targets — адресаты рассылок по кругу; пусто — все, для которых у клиентов есть атрибуты.
+	Targets []string
+	// Rate — рассылок в секунду в течение Duration; Drain — сколько ждать доставки после последней.
+	Rate        float64
+	Duration    time.Duration
+	Drain       time.Duration
+	PayloadSize int
+	BatchMS     int
+	Compression bool
+}
+
+// tracker — время отправки рассылок по порядковому номеру (с 1).
+type tracker struct {
+	sent []atomic.Int64
+}
+
+func (t *tracker) sentAt(seq int64) (time.Time, bool) {
+	if seq <= 0 || seq > int64(len(t.sent)) {
+		return time.Time{}, false
+	}
+	ns := t.sent[seq-1].Load()
+	if ns == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ns), true
+}
+
+// Report — итог теста.
+type Report struct {
+	Connected      int
+	ConnectFailed  int
+	ConnectTime    time.Duration
+	ConnectLatency Percentiles
+
+	Broadcasts  int
+	SendFailed  int
+	SendTime    time.Duration
+	SendLatency Percentiles
+
+	// Expected — доставок, ожидаемых по успешным рассылкам; Received — полученных за время теста и Drain.
+	Expected    int
+	Received    int
+	Duplicates  int
+	Disconnects int
+	Latency     Percentiles
+
+	// Server — показатели сервиса до подключений, после подключений и в конце (если /metrics доступен).
+	Server []ServerStats
+	// ClientHeapInuse — память процесса теста после рассылок.
+	ClientHeapInuse uint64
+}
+
+// Dropped — ожидаемые, но не полученные доставки.
+func (r Report) Dropped() int {
+	return max(r.Expected-r.Received, 0)
+}
+
+// Run выполняет тест: подключает клиентов, рассылает события и собирает отчёт.
+// Отмена ctx прекращает рассылки; отчёт по уже сделанному возвращается вместе с ошибкой.
+func Run(ctx context.Context, cfg Config, sender notification_service.NotificationServiceClient) (Report, error) {
+	if cfg.Clients <= 0 {
+		return Report{}, errors.New("loadtest: clients must be positive")
+	}
+	if cfg.Rate <= 0 || cfg.Duration <= 0 {
+		return Report{}, errors.New("loadtest: rate and duration must be positive")
+	}
+	if n := cfg.Rate * cfg.Duration.Seconds(); n > MaxBroadcasts {
+		return Report{}, fmt.Errorf("loadtest: rate × duration is %.0f broadcasts, at most %d allowed", n, MaxBroadcasts)
+	}
+	if cfg.ConnectRate <= 0 {
+		cfg.ConnectRate = cfg.Clients
+	}
+	targets, err := resolveTargets(cfg)
+	if err != nil {
+		return Report{}, err
+	}
+	var rep Report
+	t := &tracker{sent: make([]atomic.Int64, int(math.Ceil(cfg.Rate*cfg.Duration.Seconds())))}
+	rep.snapshot(ctx, cfg)
+
+	clients := connectAll(ctx, cfg, t, &rep)
+	if len(clients) == 0 {
+		return rep, errors.New("loadtest: no clients connected")
+	}
+	rep.snapshot(ctx, cfg)
+
+	err = broadcast(ctx, cfg, sender, targets, clients, t, &rep)
+	if err == nil {
+		select {
+		case <-time.After(cfg.Drain):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	rep.snapshot(context.WithoutCancel(ctx), cfg)
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	rep.ClientHeapInuse = ms.HeapInuse
+
+	closeAll(clients)
+	var latencies []time.Duration
+	for _, c := range clients {
+		rep.Received += len(c.latencies)
+		rep.Duplicates += c.duplicates
+		if c.disconnected {
+			rep.Disconnects++
+		}
+		latencies = append(latencies, c.latencies...)
+	}
+	rep.Latency = percentiles(latencies)
+	return rep, err
+}
+
+// resolveTargets проверяет адресатов рассылок; по умолчанию — все, для которых у клиентов есть атрибуты.
+func resolveTargets(cfg Config) ([]string, error) {
+	if len(cfg.Targets) == 0 {
+		var targets []string
+		if cfg.Sessions > 0 {
+			targets = append(targets, TargetSession)
+		}
+		if len(cfg.Regions) > 0 {
+			targets = append(targets, TargetRegion)
+		}
+		if len(cfg.Roles) > 0 {
+			targets = append(targets, TargetRole)
+		}
+		if len(targets) == 0 {
+			targets = append(targets, TargetUser)
+		}
+		return targets, nil
+	}
+	for _, target := range cfg.Targets {
+		switch {
+		case target == TargetSession && cfg.Sessions <= 0:
+			return nil, errors.New("loadtest: session target requires sessions")
+		case target == TargetRegion && len(cfg.Regions) == 0:
+			return nil, errors.New("loadtest: region target requires regions")
+		case target == TargetRole && len(cfg.Roles) == 0:
+			return nil, errors.New("loadtest: role target requires roles")
+		case !slices.Contains([]string{TargetSession, TargetRegion, TargetRole, TargetUser}, target):
+			return nil, fmt.Errorf("loadtest: unknown target %q", target)
+		}
+	}
+	return cfg.Targets, nil
+}
+
+func (r *Report) snapshot(ctx context.Context, cfg Config) {
+	if cfg.MetricsURL == "" {
+		return
+	}
+	st, err := scrape(ctx, cfg.MetricsURL)
+	if err != nil {
+		slog.WarnContext(ctx, "loadtest: metrics", "url", cfg.MetricsURL, "error", err)
+		return
+	}
+	r.Server = append(r.Server, st)
+}
+
+// connectAll подключает клиентов с частотой cfg.ConnectRate и возвращает подключившихся.
+func connectAll(ctx context.Context, cfg Config, t *tracker, rep *Report) []*client {
+	sessions := make([]uuid.UUID, cfg.Sessions)
+	for i := range sessions {
+		sessions[i] = uuid.New()
+	}
+	dialer := &websocket.Dialer{HandshakeTimeout: 10 * time.Second, EnableCompression: cfg.Compression}
+	tick := time.NewTicker(max(time.Second/time.Duration(cfg.ConnectRate), time.Microsecond))
+	defer tick.Stop()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		connected []*client
+		latencies []time.Duration
+		lastErr   error
+	)
+	start := time.Now()
+	for i := 0; i < cfg.Clients; i++ {
+		if i > 0 {
+			select {
+			case <-tick.C:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		c := newClient(pick(cfg.Regions, i), pick(cfg.Roles, i), uuid.Nil)
+		if len(sessions) > 0 {
+			c.session = sessions[i%len(sessions)]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			began := time.Now()
+			err := c.connect(ctx, cfg, dialer, t)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				rep.ConnectFailed++
+				lastErr = err
+				go c.close()
+				return
+			}
+			connected = append(connected, c)
+			latencies = append(latencies, time.Since(began))
+		}()
+	}
+	wg.Wait()
+	rep.Connected = len(connected)
+	rep.ConnectTime = time.Since(start)
+	rep.ConnectLatency = percentiles(latencies)
+	if lastErr != nil {
+		slog.WarnContext(ctx, "loadtest: connect", "failed", rep.ConnectFailed, "last_error", lastErr)
+	}
+	return connected
+}
+
+func closeAll(clients []*client) {
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.close()
+		}()
+	}
+	wg.Wait()
+}
+
+func pick(values []string, i int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[i%len(values)]
+}
+
+// audience — ожидаемые получатели рассылок по адресату.
+type audience struct {
+	sessions []uuid.UUID
+	bySess   map[uuid.UUID]int
+	byRegion map[string]int
+	byRole   map[string]int
+	users    []uuid.UUID
+}
+
+func newAudience(clients []*client) audience {
+	a := audience{bySess: map[uuid.UUID]int{}, byRegion: map[string]int{}, byRole: map[string]int{}}
+	for _, c := range clients {
+		if c.session != uuid.Nil {
+			if a.bySess[c.session] == 0 {
+				a.sessions = append(a.sessions, c.session)
+			}
+			a.bySess[c.session]++
+		}
+		a.byRegion[c.region]++
+		a.byRole[c.role]++
+		a.users = append(a.users, c.userID)
+	}
+	return a
+}
+
+// request возвращает n-ю рассылку адресату target и число её получателей.
+func (a audience) request(cfg Config, target string, n int) (*notification_service.SendNotificationRequest, int) {
+	req := &notification_service.SendNotificationRequest{Event: Event}
+	switch target {
+	case TargetSession:
+		sid := a.sessions[n%len(a.sessions)]
+		req.SessionId = sid.String()
+		return req, a.bySess[sid]
+	case TargetRegion:
+		region := cfg.Regions[n%len(cfg.Regions)]
+		req.Regions = []string{region}
+		return req, a.byRegion[region]
+	case TargetRole:
+		role := cfg.Roles[n%len(cfg.Roles)]
+		req.Roles = []string{role}
+		return req, a.byRole[role]
+	default:
+		req.UserIds = []string{a.users[n%len(a.users)].String()}
+		return req, 1
+	}
+}
+
+// broadcast рассылает события с частотой cfg.Rate в течение cfg.Duration.
+func broadcast(ctx context.Context, cfg Config, sender notification_service.NotificationServiceClient, targets []string, clients []*client, t *tracker, rep *Report) error {
+	a := newAudience(clients)
+	pad := strings.Repeat("x", cfg.PayloadSize)
+	tick := time.NewTicker(max(time.Duration(float64(time.Second)/cfg.Rate), time.Microsecond))
+	defer tick.Stop()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies []time.Duration
+		lastErr   error
+	)
+	inflight := make(chan struct{}, maxInflight)
+	start := time.Now()
+	var err error
+	for seq := int64(1); seq <= int64(len(t.sent)); seq++ {
+		if seq > 1 {
+			select {
+			case <-tick.C:
+			case <-ctx.Done():
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		n := int(seq - 1)
+		target := targets[n%len(targets)]
+		req, expected := a.request(cfg, target, n/len(targets))
+		fields := map[string]interface{}{"loadtest": map[string]interface{}{"seq": seq}}
+		if pad != "" {
+			fields["pad"] = pad
+		}
+		req.Payload, _ = structpb.NewStruct(fields)
+
+		inflight <- struct{}{}
+		wg.Add(1)
+		go func(seq int64) {
+			defer wg.Done()
+			defer func() { <-inflight }()
+			sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+			defer cancel()
+			began := time.Now()
+			t.sent[seq-1].Store(began.UnixNano())
+			_, err := sender.SendNotification(sendCtx, req)
+			mu.Lock()
+			defer mu.Unlock()
+			rep.Broadcasts++
+			if err != nil {
+				rep.SendFailed++
+				lastErr = err
+				return
+			}
+			rep.Expected += expected
+			latencies = append(latencies, time.Since(began))
+		}(seq)
+	}
+	wg.Wait()
+	rep.SendTime = time.Since(start)
+	rep.SendLatency = percentiles(latencies)
+	if lastErr != nil {
+		slog.WarnContext(ctx, "loadtest: send", "failed", rep.SendFailed, "last_error", lastErr)
+	}
+	return err
+}
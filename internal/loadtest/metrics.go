@@ -0,0 +1,73 @@
+package loadtest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerStats — показатели сервиса из /metrics в момент опроса.
+type ServerStats struct {
+	ResidentBytes  float64
+	HeapInuseBytes float64
+	Goroutines     float64
+	ConnectedUsers float64
+	// Dropped — сообщения, не поставленные в очередь отправки WebSocket (очередь полна).
+	Dropped float64
+}
+
+// scrape читает нужные метрики из текстового формата Prometheus.
+func scrape(ctx context.Context, metricsURL string) (ServerStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL, nil)
+	if err != nil {
+		return ServerStats{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ServerStats{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ServerStats{}, fmt.Errorf("metrics: %s", resp.Status)
+	}
+	var st ServerStats
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			continue
+		}
+		series := line[:i]
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			continue
+		}
+		name, labels, _ := strings.Cut(series, "{")
+		switch name {
+		case "process_resident_memory_bytes":
+			st.ResidentBytes = v
+		case "go_memstats_heap_inuse_bytes":
+			st.HeapInuseBytes = v
+		case "go_goroutines":
+			st.Goroutines = v
+		case "notification_ws_connected_users":
+			st.ConnectedUsers = v
+		case "notification_ws_messages_total":
+			if strings.Contains(labels, `result="dropped"`) {
+				st.Dropped += v
+			}
+		}
+	}
+	return st, sc.Err()
+}
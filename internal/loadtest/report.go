@@ -0,0 +1,90 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Percentiles — распределение длительностей.
+type Percentiles struct {
+	Count                    int
+	P50, P90, P99, P999, Max time.Duration
+}
+
+func percentiles(d []time.Duration) Percentiles {
+	if len(d) == 0 {
+		return Percentiles{}
+	}
+	slices.Sort(d)
+	at := func(q float64) time.Duration { return d[int(q*float64(len(d)-1))] }
+	return Percentiles{Count: len(d), P50: at(0.5), P90: at(0.9), P99: at(0.99), P999: at(0.999), Max: d[len(d)-1]}
+}
+
+func (p Percentiles) String() string {
+	if p.Count == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("p50 %s, p90 %s, p99 %s, p99.9 %s, max %s",
+		round(p.P50), round(p.P90), round(p.P99), round(p.P999), round(p.Max))
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// Print печатает отчёт.
+func (r Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "connections: %d connected, %d failed in %s (%.0f/s); handshake %s\n",
+		r.Connected, r.ConnectFailed, round(r.ConnectTime), rate(r.Connected, r.ConnectTime), r.ConnectLatency)
+	fmt.Fprintf(w, "broadcasts:  %d sent, %d failed in %s (%.1f/s); SendNotification %s\n",
+		r.Broadcasts, r.SendFailed, round(r.SendTime), rate(r.Broadcasts, r.SendTime), r.SendLatency)
+	var dropped float64
+	if r.Expected > 0 {
+		dropped = float64(r.Dropped()) / float64(r.Expected) * 100
+	}
+	fmt.Fprintf(w, "deliveries:  %d expected, %d received, %d dropped (%.2f%%), %d duplicates, %d clients disconnected\n",
+		r.Expected, r.Received, r.Dropped(), dropped, r.Duplicates, r.Disconnects)
+	fmt.Fprintf(w, "latency:     %s\n", r.Latency)
+	if len(r.Server) == 3 {
+		idle, conn, end := r.Server[0], r.Server[1], r.Server[2]
+		fmt.Fprintf(w, "server:      rss %s -> %s connected -> %s end; heap in use %s -> %s -> %s; goroutines %.0f -> %.0f -> %.0f\n",
+			size(idle.ResidentBytes), size(conn.ResidentBytes), size(end.ResidentBytes),
+			size(idle.HeapInuseBytes), size(conn.HeapInuseBytes), size(end.HeapInuseBytes),
+			idle.Goroutines, conn.Goroutines, end.Goroutines)
+		if n := conn.ConnectedUsers - idle.ConnectedUsers; n > 0 {
+			fmt.Fprintf(w, "             per connection: rss %s, heap in use %s\n",
+				size((conn.ResidentBytes-idle.ResidentBytes)/n), size((conn.HeapInuseBytes-idle.HeapInuseBytes)/n))
+		}
+		fmt.Fprintf(w, "             send queue drops: %.0f\n", end.Dropped-idle.Dropped)
+	}
+	fmt.Fprintf(w, "client:      heap in use %s\n", size(float64(r.ClientHeapInuse)))
+}
+
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+func size(b float64) string {
+	const unit = 1024
+	if b < unit && b > -unit {
+		return fmt.Sprintf("%.0f B", b)
+	}
+	div, exp := float64(unit), 0
+	for n := b / unit; n >= unit || n <= -unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", b/div, "KMGTPE"[exp])
+}
@@ -0,0 +1,94 @@
+// Package logging — структурированные логи (slog): уровень из LOG_LEVEL с изменением на лету,
+// вывод JSON или text и поля из контекста (user_id, session_id, topic, trace_id, ...).
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Level — текущий уровень логирования; меняется через SetLevel без перезапуска.
+var Level = new(slog.LevelVar)
+
+// Форматы вывода (LOG_FORMAT).
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Setup делает slog-логгер с уровнем level и форматом format логгером по умолчанию
+// (в том числе для пакета log).
+func Setup(w io.Writer, level, format string) error {
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: Level}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("logging: unknown format %q", format)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+	return nil
+}
+
+// SetLevel меняет уровень: debug, info, warn или error.
+func SetLevel(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return fmt.Errorf("logging: invalid level %q", level)
+	}
+	Level.Set(l)
+	return nil
+}
+
+type ctxKey struct{}
+
+// With возвращает контекст, записи логов в котором (slog.*Context) получат поля args.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	attrs := make([]slog.Attr, len(prev), len(prev)+len(args)/2)
+	copy(attrs, prev)
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+// contextHandler добавляет к записи поля из With и trace_id/span_id активного спана.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs, ok := ctx.Value(ctxKey{}).([]slog.Attr); ok {
+			r.AddAttrs(attrs...)
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
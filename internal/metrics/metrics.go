@@ -0,0 +1,154 @@
+// Package metrics — метрики Prometheus сервиса (хаб WebSocket, Kafka, gRPC), отдаются на /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "notification"
+
+// Registry — реестр метрик сервиса (плюс метрики Go runtime и процесса).
+var Registry = prometheus.NewRegistry()
+
+var (
+	// WSMessages — сообщения в очереди отправки WebSocket-подключений по типу адресата
+	// (user, session, region, role) и результату (sent, dropped — очередь полна).
+	WSMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "messages_total",
+		Help:      "Messages queued to WebSocket connections by target type and result.",
+	}, []string{"target", "result"})
+
+	// WSSendQueueDepth — заполненность очереди отправки подключения в момент постановки сообщения.
+	WSSendQueueDepth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "send_queue_depth",
+		Help:      "Send queue length of a WebSocket connection when a message is queued.",
+		Buckets:   []float64{0, 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024},
+	})
+
+	// WSUpgradeFailures — неудачные подключения к /ws/notify по причине (invalid_user_id, upgrade).
+	WSUpgradeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "upgrade_failures_total",
+		Help:      "Failed WebSocket connection attempts by reason.",
+	}, []string{"reason"})
+
+	// KafkaMessages — прочитанные сообщения Kafka по топику и результату маршрутизации (ok, failed).
+	KafkaMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_total",
+		Help:      "Kafka messages consumed by topic and routing result.",
+	}, []string{"topic", "result"})
+
+	// KafkaReadErrors — ошибки чтения из Kafka.
+	KafkaReadErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "read_errors_total",
+		Help:      "Errors reading from Kafka.",
+	})
+
+	// KafkaConsumerLag — отставание консьюмера по партиции: high watermark минус следующий offset.
+	KafkaConsumerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "consumer_lag",
+		Help:      "Messages between the last consumed offset and the partition high watermark.",
+	}, []string{"topic", "partition"})
+
+	// RetentionPurged — записи, удалённые по политике хранения, по таблице и действию (deleted, archived).
+	RetentionPurged = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "retention",
+		Name:      "purged_total",
+		Help:      "Rows removed by the retention policy by table and action.",
+	}, []string{"table", "action"})
+
+	// GRPCRequests — запросы gRPC по методу и коду ответа.
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+
+	// GRPCRequestDuration — длительность обработки запросов gRPC.
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC request handling duration by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		WSMessages,
+		WSSendQueueDepth,
+		WSUpgradeFailures,
+		KafkaMessages,
+		KafkaReadErrors,
+		KafkaConsumerLag,
+		RetentionPurged,
+		GRPCRequests,
+		GRPCRequestDuration,
+	)
+}
+
+// HubStats — число подключённых пользователей и непустых индексов хаба.
+type HubStats struct {
+	Users    int
+	Sessions int
+	Regions  int
+	Roles    int
+}
+
+// RegisterHub регистрирует gauge подключений; stats вызывается при каждом опросе /metrics.
+func RegisterHub(stats func() HubStats) {
+	Registry.MustRegister(&hubCollector{stats: stats})
+}
+
+type hubCollector struct {
+	stats func() HubStats
+}
+
+var hubDescs = struct {
+	users, sessions, regions, roles *prometheus.Desc
+}{
+	users:    prometheus.NewDesc(namespace+"_ws_connected_users", "Users with an active WebSocket connection.", nil, nil),
+	sessions: prometheus.NewDesc(namespace+"_ws_sessions", "Sessions with at least one subscribed user.", nil, nil),
+	regions:  prometheus.NewDesc(namespace+"_ws_regions", "Regions with at least one connected user.", nil, nil),
+	roles:    prometheus.NewDesc(namespace+"_ws_roles", "Roles with at least one connected user.", nil, nil),
+}
+
+func (c *hubCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- hubDescs.users
+	ch <- hubDescs.sessions
+	ch <- hubDescs.regions
+	ch <- hubDescs.roles
+}
+
+func (c *hubCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(hubDescs.users, prometheus.GaugeValue, float64(s.Users))
+	ch <- prometheus.MustNewConstMetric(hubDescs.sessions, prometheus.GaugeValue, float64(s.Sessions))
+	ch <- prometheus.MustNewConstMetric(hubDescs.regions, prometheus.GaugeValue, float64(s.Regions))
+	ch <- prometheus.MustNewConstMetric(hubDescs.roles, prometheus.GaugeValue, float64(s.Roles))
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
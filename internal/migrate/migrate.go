@@ -0,0 +1,245 @@
+// Package migrate применяет SQL-миграции database/migrations. Версия хранится в schema_migrations
+// в формате golang-migrate (одна строка version, dirty), так что база, размеченная его CLI, подхватывается как есть.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migration — пара файлов NNNNNN_name.up.sql / NNNNNN_name.down.sql.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status — миграция и применена ли она.
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// ErrDirty — предыдущая миграция (внешним инструментом) не завершилась; базу нужно поправить вручную.
+var ErrDirty = errors.New("migrate: database is dirty")
+
+var fileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// lockKey — ключ pg_advisory_lock: реплики, стартующие одновременно, применяют миграции по очереди.
+const lockKey = `hashtext('notification-service:migrations')`
+
+// Migrator применяет миграции к базе.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New читает миграции из fsys (корень — каталог с *.sql).
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	byVersion := make(map[uint64]*Migration)
+	for _, f := range files {
+		m := fileRe.FindStringSubmatch(f.Name())
+		if m == nil || f.IsDir() {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s: %w", f.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d has two names: %s, %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d: missing up migration", mig.Version)
+		}
+		out = append(out, *mig)
+	}
+	slices.SortFunc(out, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return &Migrator{pool: pool, migrations: out}, nil
+}
+
+// Version возвращает текущую версию базы (0 — миграции не применялись) и признак dirty.
+func (m *Migrator) Version(ctx context.Context) (uint64, bool, error) {
+	var version uint64
+	var dirty bool
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		var err error
+		version, dirty, err = readVersion(ctx, conn)
+		return err
+	})
+	return version, dirty, err
+}
+
+// Status возвращает все миграции с отметкой о применении.
+func (m *Migrator) Status(ctx context.Context) ([]Status, uint64, bool, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	out := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		out = append(out, Status{Migration: mig, Applied: mig.Version <= version})
+	}
+	return out, version, dirty, nil
+}
+
+// Up применяет до n ещё не применённых миграций (n <= 0 — все) и возвращает применённые.
+func (m *Migrator) Up(ctx context.Context, n int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		version, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, version)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= version {
+				continue
+			}
+			if n > 0 && len(applied) == n {
+				break
+			}
+			if err := m.apply(ctx, conn, mig, mig.Up, mig.Version); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down откатывает n последних применённых миграций (n <= 0 — все) и возвращает откаченные.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		version, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, version)
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version > version {
+				continue
+			}
+			if n > 0 && len(reverted) == n {
+				break
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migrate: version %d: missing down migration", mig.Version)
+			}
+			var prev uint64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+			if err := m.apply(ctx, conn, mig, mig.Down, prev); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// apply выполняет SQL миграции и записывает новую версию в одной транзакции.
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, mig Migration, sql string, version uint64) error {
+	start := time.Now()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("migrate: %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "migrate: applied", "version", mig.Version, "name", mig.Name, "schema_version", version, "duration", time.Since(start))
+	return nil
+}
+
+// withLock выполняет fn на отдельном подключении под advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	c, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	defer c.Release()
+	conn := c.Conn()
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(`+lockKey+`)`); err != nil {
+		return fmt.Errorf("migrate: lock: %w", err)
+	}
+	defer func() {
+		// Подключение может быть уже разорвано (отмена ctx) — тогда lock снят вместе с сессией.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock(`+lockKey+`)`); err != nil {
+			conn.Close(unlockCtx)
+		}
+	}()
+	if _, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	return fn(conn)
+}
+
+func readVersion(ctx context.Context, conn *pgx.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("migrate: %w", err)
+	}
+	return uint64(version), dirty, nil
+}
@@ -0,0 +1,352 @@
+// Package replay — повторная обработка событий Kafka за интервал: чтение партиций в диапазоне offset'ов
+// или времени, фильтрация и передача в Sink. Прогресс записывается в отдельную группу консьюмеров,
+// группа сервиса (KAFKA_GROUP_ID) не затрагивается.
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/routing"
+	"github.com/segmentio/kafka-go"
+)
+
+// Config — что и в каких границах переигрывать. Начало — FromOffset, FromTime или (с Resume)
+// сохранённый в Group прогресс; конец — ToOffset, ToTime или конец партиции на момент запуска.
+type Config struct {
+	Brokers []string
+	Topics  []string
+	// Partition — только эта партиция; -1 — все.
+	Partition  int
+	FromOffset int64
+	FromTime   time.Time
+	ToOffset   int64
+	ToTime     time.Time
+	// Group — группа консьюмеров для прогресса; пусто — прогресс не сохраняется.
+	Group  string
+	Resume bool
+	// ReadOnly — не записывать прогресс в Group (пробный прогон).
+	ReadOnly bool
+}
+
+// Unset — значение FromOffset/ToOffset «не задано».
+const Unset int64 = -1
+
+// Range — диапазон offset'ов [Start, End) партиции.
+type Range struct {
+	Topic     string
+	Partition int
+	Start     int64
+	End       int64
+}
+
+// Filter — отбор событий; пустые поля не применяются.
+type Filter struct {
+	Events   []string
+	Sessions []string
+	Users    []string
+}
+
+// Match проверяет событие: тип, сессию и прямых получателей (user_id, user_ids, operator_id, operator_ids).
+func (f Filter) Match(msg routing.Message) bool {
+	if len(f.Events) > 0 && !slices.Contains(f.Events, msg.Event) {
+		return false
+	}
+	if len(f.Sessions) > 0 {
+		sid, ok := msg.Session()
+		if !ok || !slices.Contains(f.Sessions, sid.String()) {
+			return false
+		}
+	}
+	if len(f.Users) > 0 {
+		found := false
+		for _, uid := range msg.DirectTargets() {
+			if slices.Contains(f.Users, uid.String()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Event — прочитанное из Kafka событие.
+type Event struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Time      time.Time
+	Raw       []byte
+	Message   routing.Message
+}
+
+// Sink получает отобранные события.
+type Sink interface {
+	Send(ctx context.Context, ev Event) error
+}
+
+// Stats — итог replay.
+type Stats struct {
+	Read     int
+	Matched  int
+	Sent     int
+	Failed   int
+	Skipped  int // не разобраны как конверт события
+	Duration time.Duration
+}
+
+// Replayer читает диапазоны партиций и передаёт отобранные события в Sink.
+type Replayer struct {
+	cfg    Config
+	client *kafka.Client
+}
+
+func New(cfg Config) (*Replayer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("replay: brokers are required")
+	}
+	if len(cfg.Topics) == 0 {
+		return nil, errors.New("replay: topics are required")
+	}
+	if cfg.FromOffset == Unset && cfg.FromTime.IsZero() && !cfg.Resume {
+		return nil, errors.New("replay: start offset or time is required")
+	}
+	if cfg.Resume && cfg.Group == "" {
+		return nil, errors.New("replay: resume requires a group")
+	}
+	if !cfg.FromTime.IsZero() && !cfg.ToTime.IsZero() && !cfg.FromTime.Before(cfg.ToTime) {
+		return nil, errors.New("replay: start time must be before end time")
+	}
+	return &Replayer{cfg: cfg, client: &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Timeout: 10 * time.Second}}, nil
+}
+
+// Plan определяет диапазоны offset'ов партиций.
+func (r *Replayer) Plan(ctx context.Context) ([]Range, error) {
+	meta, err := r.client.Metadata(ctx, &kafka.MetadataRequest{Topics: r.cfg.Topics})
+	if err != nil {
+		return nil, fmt.Errorf("replay: metadata: %w", err)
+	}
+	partitions := make(map[string][]int)
+	for _, t := range meta.Topics {
+		if t.Error != nil {
+			return nil, fmt.Errorf("replay: topic %s: %w", t.Name, t.Error)
+		}
+		for _, p := range t.Partitions {
+			if r.cfg.Partition < 0 || p.ID == r.cfg.Partition {
+				partitions[t.Name] = append(partitions[t.Name], p.ID)
+			}
+		}
+	}
+
+	// Границы партиций и offset'ы по времени — отдельными запросами: в одном запросе партиция встречается один раз.
+	bounds, err := r.listOffsets(ctx, partitions, func(p int) kafka.OffsetRequest { return kafka.LastOffsetOf(p) })
+	if err != nil {
+		return nil, err
+	}
+	firsts, err := r.listOffsets(ctx, partitions, func(p int) kafka.OffsetRequest { return kafka.FirstOffsetOf(p) })
+	if err != nil {
+		return nil, err
+	}
+	var fromTime, toTime map[string]map[int]int64
+	if !r.cfg.FromTime.IsZero() {
+		if fromTime, err = r.listOffsets(ctx, partitions, func(p int) kafka.OffsetRequest { return kafka.TimeOffsetOf(p, r.cfg.FromTime) }); err != nil {
+			return nil, err
+		}
+	}
+	if !r.cfg.ToTime.IsZero() {
+		if toTime, err = r.listOffsets(ctx, partitions, func(p int) kafka.OffsetRequest { return kafka.TimeOffsetOf(p, r.cfg.ToTime) }); err != nil {
+			return nil, err
+		}
+	}
+	var committed map[string]map[int]int64
+	if r.cfg.Resume {
+		if committed, err = r.committed(ctx, partitions); err != nil {
+			return nil, err
+		}
+	}
+
+	var ranges []Range
+	for _, topic := range r.cfg.Topics {
+		ids := partitions[topic]
+		slices.Sort(ids)
+		for _, p := range ids {
+			high := bounds[topic][p]
+			rg := Range{Topic: topic, Partition: p, Start: r.cfg.FromOffset, End: high}
+			if fromTime != nil {
+				// -1 — сообщений не раньше FromTime нет.
+				if rg.Start = fromTime[topic][p]; rg.Start < 0 {
+					rg.Start = high
+				}
+			}
+			if off, ok := committed[topic][p]; ok && off >= 0 {
+				rg.Start = off
+			}
+			rg.Start = max(rg.Start, firsts[topic][p])
+			if r.cfg.ToOffset != Unset {
+				rg.End = min(rg.End, r.cfg.ToOffset)
+			}
+			if toTime != nil {
+				if off := toTime[topic][p]; off >= 0 {
+					rg.End = min(rg.End, off)
+				}
+			}
+			if rg.Start < rg.End {
+				ranges = append(ranges, rg)
+			}
+		}
+	}
+	return ranges, nil
+}
+
+func (r *Replayer) listOffsets(ctx context.Context, partitions map[string][]int, req func(p int) kafka.OffsetRequest) (map[string]map[int]int64, error) {
+	topics := make(map[string][]kafka.OffsetRequest, len(partitions))
+	for topic, ids := range partitions {
+		for _, p := range ids {
+			topics[topic] = append(topics[topic], req(p))
+		}
+	}
+	resp, err := r.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("replay: list offsets: %w", err)
+	}
+	out := make(map[string]map[int]int64, len(resp.Topics))
+	for topic, list := range resp.Topics {
+		out[topic] = make(map[int]int64, len(list))
+		for _, p := range list {
+			if p.Error != nil {
+				return nil, fmt.Errorf("replay: list offsets %s/%d: %w", topic, p.Partition, p.Error)
+			}
+			// Ответ на FirstOffsetOf/LastOffsetOf — в FirstOffset/LastOffset (второе поле -1),
+			// на TimeOffsetOf — единственный ключ Offsets (-1, если сообщений с этого времени нет).
+			off := max(p.FirstOffset, p.LastOffset)
+			for o := range p.Offsets {
+				off = o
+			}
+			out[topic][p.Partition] = off
+		}
+	}
+	return out, nil
+}
+
+func (r *Replayer) committed(ctx context.Context, partitions map[string][]int) (map[string]map[int]int64, error) {
+	resp, err := r.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: r.cfg.Group, Topics: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("replay: fetch offsets of %s: %w", r.cfg.Group, err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("replay: fetch offsets of %s: %w", r.cfg.Group, resp.Error)
+	}
+	out := make(map[string]map[int]int64, len(resp.Topics))
+	for topic, list := range resp.Topics {
+		out[topic] = make(map[int]int64, len(list))
+		for _, p := range list {
+			if p.Error == nil {
+				out[topic][p.Partition] = p.CommittedOffset
+			}
+		}
+	}
+	return out, nil
+}
+
+// commitInterval — как часто записывать прогресс в группу во время чтения партиции.
+const commitInterval = 5 * time.Second
+
+// Run читает диапазоны по очереди и передаёт события, прошедшие фильтр, в sink.
+// Ошибка sink не останавливает replay: событие считается в Failed.
+func (r *Replayer) Run(ctx context.Context, ranges []Range, filter Filter, sink Sink) (Stats, error) {
+	var st Stats
+	start := time.Now()
+	for _, rg := range ranges {
+		if err := r.runRange(ctx, rg, filter, sink, &st); err != nil {
+			st.Duration = time.Since(start)
+			return st, err
+		}
+	}
+	st.Duration = time.Since(start)
+	return st, nil
+}
+
+func (r *Replayer) runRange(ctx context.Context, rg Range, filter Filter, sink Sink, st *Stats) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   r.cfg.Brokers,
+		Topic:     rg.Topic,
+		Partition: rg.Partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+		MaxWait:   time.Second,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(rg.Start); err != nil {
+		return fmt.Errorf("replay: %s/%d: %w", rg.Topic, rg.Partition, err)
+	}
+	log := slog.With("topic", rg.Topic, "partition", rg.Partition)
+	log.InfoContext(ctx, "replay: partition", "start", rg.Start, "end", rg.End)
+	next := rg.Start
+	lastCommit := time.Now()
+	for next < rg.End {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			r.commit(rg, next)
+			return fmt.Errorf("replay: %s/%d: %w", rg.Topic, rg.Partition, err)
+		}
+		if m.Offset >= rg.End {
+			break
+		}
+		if time.Since(lastCommit) >= commitInterval {
+			r.commit(rg, next)
+			lastCommit = time.Now()
+		}
+		next = m.Offset + 1
+		st.Read++
+		msg, err := routing.ParseMessage(m.Value)
+		if err != nil {
+			st.Skipped++
+			log.WarnContext(ctx, "replay: skip", "offset", m.Offset, "error", err)
+			continue
+		}
+		if !filter.Match(msg) {
+			continue
+		}
+		st.Matched++
+		ev := Event{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset, Time: m.Time, Raw: m.Value, Message: msg}
+		if err := sink.Send(ctx, ev); err != nil {
+			st.Failed++
+			log.ErrorContext(ctx, "replay: send", "offset", m.Offset, "event", msg.Event, "error", err)
+			continue
+		}
+		st.Sent++
+	}
+	r.commit(rg, rg.End)
+	return nil
+}
+
+// commit записывает прогресс партиции в группу replay (offset следующего сообщения).
+func (r *Replayer) commit(rg Range, next int64) {
+	if r.cfg.Group == "" || r.cfg.ReadOnly {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := r.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      r.cfg.Group,
+		GenerationID: -1,
+		Topics:       map[string][]kafka.OffsetCommit{rg.Topic: {{Partition: rg.Partition, Offset: next}}},
+	})
+	if err == nil {
+		for _, p := range resp.Topics[rg.Topic] {
+			if p.Error != nil {
+				err = p.Error
+			}
+		}
+	}
+	if err != nil {
+		slog.Warn("replay: commit progress", "group", r.cfg.Group, "topic", rg.Topic, "partition", rg.Partition, "offset", next, "error", err)
+	}
+}
@@ -0,0 +1,145 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/grpc/metadata"
+)
+
+// RouteSink отправляет конверт события как есть в SendNotification запущенного сервиса:
+// тот же конвейер маршрутизации, что у RunConsumer (WebSocket, история, внешние каналы, webhooks).
+type RouteSink struct {
+	Client notification_service.NotificationServiceClient
+}
+
+// Send отправляет конверт исходными байтами (raw_message), чтобы числа payload не округлялись до double.
+func (s RouteSink) Send(ctx context.Context, ev Event) error {
+	_, err := s.Client.SendNotification(ctx, &notification_service.SendNotificationRequest{RawMessage: ev.Raw})
+	return err
+}
+
+// PendingSaver — очередь кадров для отправки при следующем подключении (notification_pending).
+type PendingSaver interface {
+	Save(ctx context.Context, userID uuid.UUID, frames [][]byte) error
+}
+
+// ErrNoDirectRecipients — у события нет прямых получателей: подписчиков сессии, регионы и роли знает
+// только NotifyHub запущенного сервиса, поэтому в офлайн-очередь такое событие не попадает.
+var ErrNoDirectRecipients = errors.New("event has no direct recipients")
+
+// OfflineSink кладёт кадр события в офлайн-очередь прямых получателей: он будет отправлен при их
+// следующем подключении. История и внешние каналы не затрагиваются.
+type OfflineSink struct {
+	Pending PendingSaver
+}
+
+func (s OfflineSink) Send(ctx context.Context, ev Event) error {
+	targets := ev.Message.DirectTargets()
+	if len(targets) == 0 {
+		return ErrNoDirectRecipients
+	}
+	for _, uid := range targets {
+		if err := s.Pending.Save(ctx, uid, [][]byte{ev.Raw}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolver — получатели, известные только NotifyHub запущенного сервиса (admin API).
+type Resolver interface {
+	SessionSubscribers(ctx context.Context, sessionID string) ([]string, error)
+	Connected(ctx context.Context, region, role string) ([]string, error)
+}
+
+// DryRunSink печатает событие и его получателей, ничего не отправляя. Без Resolver подписчики
+// сессии, регионы и роли печатаются без раскрытия.
+type DryRunSink struct {
+	Out      io.Writer
+	Resolver Resolver
+}
+
+func (s DryRunSink) Send(ctx context.Context, ev Event) error {
+	msg := ev.Message
+	var lines []string
+	direct := make([]string, 0, len(msg.DirectTargets()))
+	for _, uid := range msg.DirectTargets() {
+		direct = append(direct, uid.String())
+	}
+	if len(direct) > 0 {
+		lines = append(lines, "direct: "+strings.Join(direct, ", "))
+	}
+	if sid, ok := msg.Session(); ok {
+		lines = append(lines, s.resolve(ctx, "session "+sid.String(), func() ([]string, error) {
+			return s.Resolver.SessionSubscribers(ctx, sid.String())
+		}))
+	}
+	for _, region := range msg.Regions {
+		lines = append(lines, s.resolve(ctx, "region "+region, func() ([]string, error) {
+			return s.Resolver.Connected(ctx, region, "")
+		}))
+	}
+	for _, role := range msg.Roles {
+		lines = append(lines, s.resolve(ctx, "role "+role, func() ([]string, error) {
+			return s.Resolver.Connected(ctx, "", role)
+		}))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no recipients")
+	}
+	_, err := fmt.Fprintf(s.Out, "%s %s/%d@%d %s\n  %s\n", ev.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		ev.Topic, ev.Partition, ev.Offset, msg.Event, strings.Join(lines, "\n  "))
+	return err
+}
+
+func (s DryRunSink) resolve(ctx context.Context, label string, fn func() ([]string, error)) string {
+	if s.Resolver == nil {
+		return label + ": (not resolved, set ADMIN_TOKEN)"
+	}
+	users, err := fn()
+	if err != nil {
+		return fmt.Sprintf("%s: error: %v", label, err)
+	}
+	if len(users) == 0 {
+		return label + ": nobody connected"
+	}
+	slices.Sort(users)
+	return label + ": " + strings.Join(users, ", ")
+}
+
+// AdminResolver — Resolver поверх NotificationAdminService; Token — bearer-токен admin API.
+type AdminResolver struct {
+	Client notification_service.NotificationAdminServiceClient
+	Token  string
+}
+
+func (r AdminResolver) auth(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+r.Token)
+}
+
+func (r AdminResolver) SessionSubscribers(ctx context.Context, sessionID string) ([]string, error) {
+	resp, err := r.Client.ListSessionSubscribers(r.auth(ctx), &notification_service.ListSessionSubscribersRequest{SessionId: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetUserIds(), nil
+}
+
+func (r AdminResolver) Connected(ctx context.Context, region, role string) ([]string, error) {
+	resp, err := r.Client.ListConnections(r.auth(ctx), &notification_service.ListConnectionsRequest{Region: region, Role: role})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]string, 0, len(resp.GetConnections()))
+	for _, c := range resp.GetConnections() {
+		users = append(users, c.GetUserId())
+	}
+	return users, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound — запись не найдена.
+var ErrNotFound = errors.New("not found")
+
+// ContactRepository хранит контактные данные пользователей для внешних каналов (email).
+type ContactRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewContactRepository(pool *pgxpool.Pool) *ContactRepository {
+	return &ContactRepository{pool: pool}
+}
+
+// SetEmail сохраняет (или заменяет) email пользователя.
+func (r *ContactRepository) SetEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_contacts (user_id, email, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email, updated_at = CURRENT_TIMESTAMP`,
+		userID, email)
+	return err
+}
+
+// Email возвращает email пользователя или ErrNotFound.
+func (r *ContactRepository) Email(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email *string
+	err := r.pool.QueryRow(ctx, `SELECT email FROM notification_contacts WHERE user_id = $1`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && (email == nil || *email == "")) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return *email, nil
+}
+
+// SetLocale сохраняет язык пользователя для шаблонов уведомлений; пустая строка сбрасывает язык.
+func (r *ContactRepository) SetLocale(ctx context.Context, userID uuid.UUID, locale string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_contacts (user_id, locale, updated_at)
+		VALUES ($1, NULLIF($2, ''), CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET locale = EXCLUDED.locale, updated_at = CURRENT_TIMESTAMP`,
+		userID, locale)
+	return err
+}
+
+// Locales возвращает языки пользователей, у которых он задан.
+func (r *ContactRepository) Locales(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, locale FROM notification_contacts
+		WHERE user_id = ANY($1) AND locale IS NOT NULL`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var uid uuid.UUID
+		var locale string
+		if err := rows.Scan(&uid, &locale); err != nil {
+			return nil, err
+		}
+		out[uid] = locale
+	}
+	return out, rows.Err()
+}
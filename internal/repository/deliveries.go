@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Статусы доставки во внешние каналы.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Event — запись notification_events.
+type Event struct {
+	ID        uuid.UUID
+	SessionID uuid.NullUUID
+	UserID    uuid.UUID
+	EventType string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Delivery — попытка доставки события пользователю через канал (email и т.п.).
+type Delivery struct {
+	ID       uuid.UUID
+	Channel  string
+	Status   string
+	Attempts int
+	Event    Event
+}
+
+// DeliveryRepository — очередь доставок во внешние каналы поверх notification_deliveries.
+type DeliveryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeliveryRepository(pool *pgxpool.Pool) *DeliveryRepository {
+	return &DeliveryRepository{pool: pool}
+}
+
+// Enqueue ставит доставку события notificationID получателям в очередь канала. Доставка ссылается на
+// запись события получателя (notification_events по notification_id и user_id): запись из истории или
+// из очереди другого канала используется повторно, иначе создаётся. Доставка начнётся не раньше
+// notBefore (нулевое значение — сразу).
+func (r *DeliveryRepository) Enqueue(ctx context.Context, channel string, notificationID uuid.UUID, sessionID uuid.NullUUID, eventType string, payload json.RawMessage, userIDs []uuid.UUID, notBefore time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var nextAttempt *time.Time
+	if !notBefore.IsZero() {
+		nextAttempt = &notBefore
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO notification_events (notification_id, session_id, user_id, event_type, payload)
+		SELECT $1, $2, u, $3, $4 FROM unnest($5::uuid[]) AS u
+		ON CONFLICT (notification_id, user_id) WHERE notification_id IS NOT NULL DO NOTHING`,
+		notificationID, sessionID, eventType, nullJSON(payload), userIDs); err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO notification_deliveries (event_id, user_id, channel, next_attempt_at)
+		SELECT e.id, e.user_id, $3::varchar, COALESCE($4::timestamptz, CURRENT_TIMESTAMP)
+		FROM notification_events e
+		WHERE e.notification_id = $1 AND e.user_id = ANY($2)`,
+		notificationID, userIDs, channel, nextAttempt); err != nil {
+		return fmt.Errorf("insert delivery: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimDue забирает до limit доставок, срок которых наступил, и откладывает их на lease:
+// если процесс упадёт во время отправки, доставка вернётся в очередь после истечения lease.
+// FOR UPDATE SKIP LOCKED позволяет нескольким репликам разбирать очередь без двойной отправки.
+func (r *DeliveryRepository) ClaimDue(ctx context.Context, channels []string, limit int, lease time.Duration) ([]Delivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP AND channel = ANY($1)
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_deliveries d
+		SET attempts = d.attempts + 1,
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3),
+		    updated_at = CURRENT_TIMESTAMP
+		FROM due, notification_events e
+		WHERE d.id = due.id AND e.id = d.event_id
+		RETURNING d.id, d.channel, d.status, d.attempts,
+		          e.id, e.session_id, e.user_id, e.event_type, e.payload, e.created_at`,
+		channels, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Delivery, error) {
+		var d Delivery
+		var payload []byte
+		err := row.Scan(&d.ID, &d.Channel, &d.Status, &d.Attempts,
+			&d.Event.ID, &d.Event.SessionID, &d.Event.UserID, &d.Event.EventType, &payload, &d.Event.CreatedAt)
+		d.Event.Payload = payload
+		return d, err
+	})
+}
+
+// MarkDelivered отмечает доставку как успешную.
+func (r *DeliveryRepository) MarkDelivered(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = 'delivered', last_error = NULL, delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	return err
+}
+
+// MarkRetry возвращает доставку в очередь с новой попыткой в nextAttempt.
+func (r *DeliveryRepository) MarkRetry(ctx context.Context, id uuid.UUID, nextAttempt time.Time, lastErr string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET last_error = $2, next_attempt_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, lastErr, nextAttempt)
+	return err
+}
+
+// MarkFailed отмечает доставку как окончательно неуспешную.
+func (r *DeliveryRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastErr string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = 'failed', last_error = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, lastErr)
+	return err
+}
+
+// nullJSON превращает пустой payload в SQL NULL, чтобы JSONB не получил невалидное значение.
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Платформы устройств для push-уведомлений.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// Device — зарегистрированный push-токен устройства пользователя.
+type Device struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Platform  string
+	Token     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DeviceRepository — реестр push-токенов устройств.
+type DeviceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeviceRepository(pool *pgxpool.Pool) *DeviceRepository {
+	return &DeviceRepository{pool: pool}
+}
+
+// Register сохраняет токен устройства. Токен уникален: если он был привязан
+// к другому пользователю (смена аккаунта на устройстве), он переходит к новому.
+func (r *DeviceRepository) Register(ctx context.Context, userID uuid.UUID, platform, token string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_tokens (user_id, platform, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, updated_at = CURRENT_TIMESTAMP`,
+		userID, platform, token)
+	return err
+}
+
+// Unregister удаляет токен пользователя; ErrNotFound, если такого токена у пользователя нет.
+func (r *DeviceRepository) Unregister(ctx context.Context, userID uuid.UUID, token string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM device_tokens WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteToken удаляет токен, который провайдер счёл недействительным.
+func (r *DeviceRepository) DeleteToken(ctx context.Context, token string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM device_tokens WHERE token = $1`, token)
+	return err
+}
+
+// ListByUser возвращает устройства пользователя.
+func (r *DeviceRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Device, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, platform, token, created_at, updated_at
+		FROM device_tokens WHERE user_id = $1
+		ORDER BY updated_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Device, error) {
+		var d Device
+		err := row.Scan(&d.ID, &d.UserID, &d.Platform, &d.Token, &d.CreatedAt, &d.UpdatedAt)
+		return d, err
+	})
+}
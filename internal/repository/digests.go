@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Digest — настройка дайджеста пользователя: подходящие события не доставляются по одному,
+// а раз в Interval собираются в одно уведомление, которое уходит через Channel.
+type Digest struct {
+	UserID   uuid.UUID
+	Interval time.Duration
+	Channel  string
+	// Events — события, собираемые в дайджест; пусто — события с priority = low.
+	Events    []string
+	NextRunAt time.Time
+	UpdatedAt time.Time
+}
+
+// DigestRepository хранит настройки дайджестов и накопленные для них события (notification_events.digest_status).
+type DigestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDigestRepository(pool *pgxpool.Pool) *DigestRepository {
+	return &DigestRepository{pool: pool}
+}
+
+const digestColumns = `user_id, interval_seconds, channel, events, next_run_at, updated_at`
+
+func scanDigest(row pgx.Row) (Digest, error) {
+	var d Digest
+	var seconds int
+	err := row.Scan(&d.UserID, &seconds, &d.Channel, &d.Events, &d.NextRunAt, &d.UpdatedAt)
+	d.Interval = time.Duration(seconds) * time.Second
+	return d, err
+}
+
+// Set сохраняет (или заменяет) настройку дайджеста. Следующий дайджест — через Interval,
+// но не позже уже запланированного.
+func (r *DigestRepository) Set(ctx context.Context, d Digest) (Digest, error) {
+	if d.Events == nil {
+		d.Events = []string{}
+	}
+	return scanDigest(r.pool.QueryRow(ctx, `
+		INSERT INTO notification_digests (user_id, interval_seconds, channel, events, next_run_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE
+		SET interval_seconds = EXCLUDED.interval_seconds, channel = EXCLUDED.channel, events = EXCLUDED.events,
+		    next_run_at = LEAST(notification_digests.next_run_at, EXCLUDED.next_run_at), updated_at = CURRENT_TIMESTAMP
+		RETURNING `+digestColumns,
+		d.UserID, int(d.Interval/time.Second), d.Channel, d.Events, time.Now().Add(d.Interval)))
+}
+
+// Get возвращает настройку дайджеста пользователя или ErrNotFound.
+func (r *DigestRepository) Get(ctx context.Context, userID uuid.UUID) (Digest, error) {
+	d, err := scanDigest(r.pool.QueryRow(ctx, `
+		SELECT `+digestColumns+` FROM notification_digests WHERE user_id = $1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d, ErrNotFound
+	}
+	return d, err
+}
+
+// Delete отключает дайджест; накопленные и ещё не отправленные события отбрасываются.
+func (r *DigestRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `DELETE FROM notification_digests WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE notification_events SET digest_status = 'discarded'
+		WHERE user_id = $1 AND digest_status = 'pending'`, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ForUsers возвращает настройки дайджестов пользователей, у которых они есть.
+func (r *DigestRepository) ForUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]Digest, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+digestColumns+` FROM notification_digests WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Digest, error) {
+		return scanDigest(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]Digest, len(list))
+	for _, d := range list {
+		out[d.UserID] = d
+	}
+	return out, nil
+}
+
+// Collect сохраняет событие для каждого пользователя в ожидании дайджеста.
+func (r *DigestRepository) Collect(ctx context.Context, sessionID uuid.NullUUID, eventType string, payload json.RawMessage, userIDs []uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_events (session_id, user_id, event_type, payload, digest_status)
+		SELECT $1, uid, $2::varchar, $3::jsonb, 'pending' FROM unnest($4::uuid[]) AS uid`,
+		sessionID, eventType, nullJSON(payload), userIDs)
+	return err
+}
+
+// ClaimDue забирает до limit дайджестов, срок которых наступил, и сразу планирует следующий запуск:
+// FOR UPDATE SKIP LOCKED не даёт двум репликам собрать один дайджест.
+func (r *DigestRepository) ClaimDue(ctx context.Context, limit int) ([]Digest, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE notification_digests d
+		SET next_run_at = CURRENT_TIMESTAMP + make_interval(secs => d.interval_seconds)
+		WHERE d.user_id IN (
+			SELECT user_id FROM notification_digests
+			WHERE next_run_at <= CURRENT_TIMESTAMP
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING d.user_id, d.interval_seconds, d.channel, d.events, d.next_run_at, d.updated_at`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Digest, error) {
+		return scanDigest(row)
+	})
+}
+
+// PendingEvents возвращает до limit самых старых событий, ожидающих дайджеста пользователя.
+func (r *DigestRepository) PendingEvents(ctx context.Context, userID uuid.UUID, limit int) ([]Event, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, session_id, user_id, event_type, payload, created_at
+		FROM notification_events
+		WHERE user_id = $1 AND digest_status = 'pending'
+		ORDER BY created_at, id
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Event, error) {
+		var ev Event
+		var payload []byte
+		err := row.Scan(&ev.ID, &ev.SessionID, &ev.UserID, &ev.EventType, &payload, &ev.CreatedAt)
+		ev.Payload = payload
+		return ev, err
+	})
+}
+
+// MarkSent отмечает события как вошедшие в дайджест.
+func (r *DigestRepository) MarkSent(ctx context.Context, eventIDs []uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_events SET digest_status = 'sent' WHERE id = ANY($1)`, eventIDs)
+	return err
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Notification — уведомление в истории пользователя.
+type Notification struct {
+	// ID — идентификатор записи (позиция в пагинации).
+	ID uuid.UUID
+	// NotificationID — идентификатор события, общий для всех его получателей (notification_id в кадре WebSocket).
+	NotificationID uuid.UUID
+	SessionID      uuid.NullUUID
+	UserID         uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	CreatedAt      time.Time
+	SeenAt         *time.Time
+	ReadAt         *time.Time
+	DismissedAt    *time.Time
+}
+
+// Состояния уведомления в истории получателя. Прочитанное считается и просмотренным;
+// непрочитанные — не прочитанные и не скрытые.
+const (
+	StateSeen      = "seen"
+	StateRead      = "read"
+	StateDismissed = "dismissed"
+)
+
+// ErrInvalidState — неизвестное состояние уведомления.
+var ErrInvalidState = errors.New("state must be seen, read or dismissed")
+
+// markSet — SET и условие «состояние ещё не выставлено» для UPDATE по состоянию.
+var markSet = map[string]string{
+	StateSeen:      `seen_at = CURRENT_TIMESTAMP WHERE seen_at IS NULL`,
+	StateRead:      `read_at = CURRENT_TIMESTAMP, seen_at = COALESCE(seen_at, CURRENT_TIMESTAMP) WHERE read_at IS NULL`,
+	StateDismissed: `dismissed_at = CURRENT_TIMESTAMP WHERE dismissed_at IS NULL`,
+}
+
+// HistoryQuery — фильтры истории уведомлений; пустые поля не применяются.
+type HistoryQuery struct {
+	UserID     uuid.NullUUID
+	SessionID  uuid.NullUUID
+	EventTypes []string
+	// Since и Until — полуинтервал [Since, Until) по времени события.
+	Since time.Time
+	Until time.Time
+	// UnreadOnly — только непрочитанные; ExcludeDismissed — без скрытых.
+	UnreadOnly       bool
+	ExcludeDismissed bool
+	After            *Cursor
+	Limit            int
+}
+
+// HistoryRepository — история уведомлений: записи notification_events с history = true,
+// по одной на получателя события.
+type HistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHistoryRepository(pool *pgxpool.Pool) *HistoryRepository {
+	return &HistoryRepository{pool: pool}
+}
+
+// Record сохраняет событие notificationID в историю каждого получателя. Строку, уже созданную
+// очередью доставок (DeliveryRepository.Enqueue) для того же получателя, помечает как историю.
+func (r *HistoryRepository) Record(ctx context.Context, notificationID uuid.UUID, sessionID uuid.NullUUID, eventType string, payload json.RawMessage, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_events (notification_id, session_id, user_id, event_type, payload, history)
+		SELECT $1, $2, u, $3, $4, true FROM unnest($5::uuid[]) AS u
+		ON CONFLICT (notification_id, user_id) WHERE notification_id IS NOT NULL DO UPDATE SET history = true`,
+		notificationID, sessionID, eventType, nullJSON(payload), userIDs)
+	return err
+}
+
+const notificationColumns = `id, notification_id, session_id, user_id, event_type, payload, created_at, seen_at, read_at, dismissed_at`
+
+// List возвращает историю от новых событий к старым (keyset-пагинация по Cursor).
+func (r *HistoryRepository) List(ctx context.Context, q HistoryQuery) ([]Notification, error) {
+	var since, until, afterTime *time.Time
+	var afterID uuid.UUID
+	if !q.Since.IsZero() {
+		since = &q.Since
+	}
+	if !q.Until.IsZero() {
+		until = &q.Until
+	}
+	if q.After != nil {
+		afterTime, afterID = &q.After.CreatedAt, q.After.ID
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notification_events
+		WHERE history
+		  AND ($1::uuid IS NULL OR user_id = $1)
+		  AND ($2::uuid IS NULL OR session_id = $2)
+		  AND (COALESCE(cardinality($3::text[]), 0) = 0 OR event_type = ANY($3))
+		  AND ($4::timestamptz IS NULL OR created_at >= $4)
+		  AND ($5::timestamptz IS NULL OR created_at < $5)
+		  AND ($6::timestamptz IS NULL OR (created_at, id) < ($6, $7))
+		  AND (NOT $8 OR (read_at IS NULL AND dismissed_at IS NULL))
+		  AND (NOT $9 OR dismissed_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $10`,
+		q.UserID, q.SessionID, q.EventTypes, since, until, afterTime, afterID, q.UnreadOnly, q.ExcludeDismissed, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanNotification)
+}
+
+func scanNotification(row pgx.CollectableRow) (Notification, error) {
+	var n Notification
+	var payload []byte
+	err := row.Scan(&n.ID, &n.NotificationID, &n.SessionID, &n.UserID, &n.EventType, &payload, &n.CreatedAt,
+		&n.SeenAt, &n.ReadAt, &n.DismissedAt)
+	n.Payload = payload
+	return n, err
+}
+
+// Mark выставляет состояние уведомлениям пользователя и возвращает, скольким из них оно изменилось.
+func (r *HistoryRepository) Mark(ctx context.Context, userID uuid.UUID, state string, notificationIDs []uuid.UUID) (int64, error) {
+	set, ok := markSet[state]
+	if !ok {
+		return 0, ErrInvalidState
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE notification_events SET `+set+`
+		  AND history AND user_id = $1 AND notification_id = ANY($2)`,
+		userID, notificationIDs)
+	return tag.RowsAffected(), err
+}
+
+// MarkBefore — Mark для всех уведомлений пользователя, созданных раньше before.
+func (r *HistoryRepository) MarkBefore(ctx context.Context, userID uuid.UUID, state string, before time.Time) (int64, error) {
+	set, ok := markSet[state]
+	if !ok {
+		return 0, ErrInvalidState
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE notification_events SET `+set+`
+		  AND history AND user_id = $1 AND created_at < $2`,
+		userID, before)
+	return tag.RowsAffected(), err
+}
+
+// UnreadCounts возвращает число непрочитанных уведомлений пользователей (нет в ответе — ноль).
+func (r *HistoryRepository) UnreadCounts(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	out := make(map[uuid.UUID]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, count(*) FROM notification_events
+		WHERE history AND read_at IS NULL AND dismissed_at IS NULL AND user_id = ANY($1)
+		GROUP BY user_id`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var uid uuid.UUID
+		var n int
+		if err := rows.Scan(&uid, &n); err != nil {
+			return nil, err
+		}
+		out[uid] = n
+	}
+	return out, rows.Err()
+}
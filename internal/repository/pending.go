@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PendingRepository хранит кадры WebSocket, не отправленные пользователю до остановки реплики
+// (notification_pending); они отправляются при следующем подключении пользователя.
+type PendingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPendingRepository(pool *pgxpool.Pool) *PendingRepository {
+	return &PendingRepository{pool: pool}
+}
+
+// Save сохраняет кадры пользователя в порядке отправки. Кадры — JSON-объекты; event_type берётся из поля event.
+func (r *PendingRepository) Save(ctx context.Context, userID uuid.UUID, frames [][]byte) error {
+	if len(frames) == 0 {
+		return nil
+	}
+	events := make([]string, len(frames))
+	payloads := make([]string, len(frames))
+	for i, f := range frames {
+		var head struct {
+			Event string `json:"event"`
+			Type  string `json:"type"`
+		}
+		_ = json.Unmarshal(f, &head)
+		switch {
+		case head.Event != "":
+			events[i] = head.Event
+		case head.Type != "":
+			events[i] = head.Type
+		default:
+			events[i] = "unknown"
+		}
+		if len(events[i]) > 64 {
+			events[i] = events[i][:64]
+		}
+		payloads[i] = string(f)
+	}
+	// Порядок кадров сохраняется в created_at: микросекунда на кадр от общего CURRENT_TIMESTAMP.
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_pending (user_id, event_type, payload, created_at)
+		SELECT $1, t.event_type, t.payload::jsonb, CURRENT_TIMESTAMP + t.n * INTERVAL '1 microsecond'
+		FROM unnest($2::text[], $3::text[]) WITH ORDINALITY AS t(event_type, payload, n)`,
+		userID, events, payloads)
+	return err
+}
+
+// Take забирает (удаляет и возвращает) до limit самых старых кадров пользователя.
+func (r *PendingRepository) Take(ctx context.Context, userID uuid.UUID, limit int) ([][]byte, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH taken AS (
+			DELETE FROM notification_pending
+			WHERE id IN (
+				SELECT id FROM notification_pending
+				WHERE user_id = $1
+				ORDER BY created_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING payload, created_at
+		)
+		SELECT payload FROM taken ORDER BY created_at`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) ([]byte, error) {
+		var payload []byte
+		err := row.Scan(&payload)
+		return payload, err
+	})
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultPreferenceEvent — event_type настройки, действующей для всех событий без собственной настройки.
+const DefaultPreferenceEvent = "*"
+
+// Каналы доставки, которыми пользователь управляет в настройках.
+const (
+	ChannelWebSocket = "websocket"
+	ChannelEmail     = "email"
+	ChannelPush      = "push"
+	ChannelWebhook   = "webhook"
+)
+
+// Preference — настройка уведомлений пользователя для типа события.
+type Preference struct {
+	UserID    uuid.UUID
+	EventType string
+	// Channels — разрешённые каналы; nil — все каналы.
+	Channels  []string
+	Muted     bool
+	UpdatedAt time.Time
+}
+
+// Allows сообщает, разрешена ли доставка через канал.
+func (p Preference) Allows(channel string) bool {
+	if p.Muted {
+		return false
+	}
+	if p.Channels == nil {
+		return true
+	}
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferenceRepository хранит настройки уведомлений пользователей.
+type PreferenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPreferenceRepository(pool *pgxpool.Pool) *PreferenceRepository {
+	return &PreferenceRepository{pool: pool}
+}
+
+const preferenceColumns = `user_id, event_type, channels, muted, updated_at`
+
+func scanPreference(row pgx.Row) (Preference, error) {
+	var p Preference
+	err := row.Scan(&p.UserID, &p.EventType, &p.Channels, &p.Muted, &p.UpdatedAt)
+	return p, err
+}
+
+// Set сохраняет (или заменяет) настройку пользователя для типа события.
+func (r *PreferenceRepository) Set(ctx context.Context, p Preference) (Preference, error) {
+	return scanPreference(r.pool.QueryRow(ctx, `
+		INSERT INTO notification_preferences (user_id, event_type, channels, muted, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, event_type) DO UPDATE
+		SET channels = EXCLUDED.channels, muted = EXCLUDED.muted, updated_at = CURRENT_TIMESTAMP
+		RETURNING `+preferenceColumns,
+		p.UserID, p.EventType, p.Channels, p.Muted))
+}
+
+// List возвращает все настройки пользователя.
+func (r *PreferenceRepository) List(ctx context.Context, userID uuid.UUID) ([]Preference, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+preferenceColumns+`
+		FROM notification_preferences WHERE user_id = $1
+		ORDER BY event_type`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Preference, error) {
+		return scanPreference(row)
+	})
+}
+
+// Delete удаляет настройку; ErrNotFound, если её нет.
+func (r *PreferenceRepository) Delete(ctx context.Context, userID uuid.UUID, eventType string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM notification_preferences WHERE user_id = $1 AND event_type = $2`, userID, eventType)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Resolve возвращает действующие настройки пользователей для события: настройка
+// события важнее настройки по умолчанию. Пользователи без настроек в результат не попадают.
+func (r *PreferenceRepository) Resolve(ctx context.Context, userIDs []uuid.UUID, eventType string) (map[uuid.UUID]Preference, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (user_id) `+preferenceColumns+`
+		FROM notification_preferences
+		WHERE user_id = ANY($1) AND event_type IN ($2, '*')
+		ORDER BY user_id, (event_type = '*')`, userIDs, eventType)
+	if err != nil {
+		return nil, err
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Preference, error) {
+		return scanPreference(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]Preference, len(list))
+	for _, p := range list {
+		out[p.UserID] = p
+	}
+	return out, nil
+}
+
+// Get возвращает настройку пользователя для типа события или ErrNotFound.
+func (r *PreferenceRepository) Get(ctx context.Context, userID uuid.UUID, eventType string) (Preference, error) {
+	p, err := scanPreference(r.pool.QueryRow(ctx, `
+		SELECT `+preferenceColumns+`
+		FROM notification_preferences WHERE user_id = $1 AND event_type = $2`, userID, eventType))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrNotFound
+	}
+	return p, err
+}
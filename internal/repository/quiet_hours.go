@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuietHours — окно тишины пользователя в его часовом поясе: с Start до End минут от полуночи.
+// Если End < Start, окно переходит через полночь (например, 22:00–07:00).
+type QuietHours struct {
+	UserID    uuid.UUID
+	Timezone  string
+	Start     int
+	End       int
+	UpdatedAt time.Time
+}
+
+// Until возвращает момент окончания окна, если now попадает в окно тишины.
+func (q QuietHours) Until(now time.Time) (time.Time, bool) {
+	if q.Start == q.End {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endDay := 0
+	switch {
+	case q.Start < q.End:
+		if minute < q.Start || minute >= q.End {
+			return time.Time{}, false
+		}
+	case minute >= q.Start:
+		endDay = 1
+	case minute >= q.End:
+		return time.Time{}, false
+	}
+	y, m, d := local.Date()
+	return time.Date(y, m, d+endDay, q.End/60, q.End%60, 0, 0, loc), true
+}
+
+// QuietHoursRepository хранит окна тишины пользователей.
+type QuietHoursRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewQuietHoursRepository(pool *pgxpool.Pool) *QuietHoursRepository {
+	return &QuietHoursRepository{pool: pool}
+}
+
+const quietHoursColumns = `user_id, timezone, start_minute, end_minute, updated_at`
+
+func scanQuietHours(row pgx.Row) (QuietHours, error) {
+	var q QuietHours
+	err := row.Scan(&q.UserID, &q.Timezone, &q.Start, &q.End, &q.UpdatedAt)
+	return q, err
+}
+
+// Set сохраняет (или заменяет) окно тишины пользователя.
+func (r *QuietHoursRepository) Set(ctx context.Context, q QuietHours) (QuietHours, error) {
+	return scanQuietHours(r.pool.QueryRow(ctx, `
+		INSERT INTO notification_quiet_hours (user_id, timezone, start_minute, end_minute, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE
+		SET timezone = EXCLUDED.timezone, start_minute = EXCLUDED.start_minute,
+		    end_minute = EXCLUDED.end_minute, updated_at = CURRENT_TIMESTAMP
+		RETURNING `+quietHoursColumns,
+		q.UserID, q.Timezone, q.Start, q.End))
+}
+
+// Get возвращает окно тишины пользователя или ErrNotFound.
+func (r *QuietHoursRepository) Get(ctx context.Context, userID uuid.UUID) (QuietHours, error) {
+	q, err := scanQuietHours(r.pool.QueryRow(ctx, `
+		SELECT `+quietHoursColumns+` FROM notification_quiet_hours WHERE user_id = $1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return q, ErrNotFound
+	}
+	return q, err
+}
+
+// Delete удаляет окно тишины; ErrNotFound, если его нет.
+func (r *QuietHoursRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM notification_quiet_hours WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// QuietUntil возвращает для пользователей, у которых сейчас окно тишины, момент его окончания.
+func (r *QuietHoursRepository) QuietUntil(ctx context.Context, userIDs []uuid.UUID, now time.Time) (map[uuid.UUID]time.Time, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+quietHoursColumns+` FROM notification_quiet_hours WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (QuietHours, error) {
+		return scanQuietHours(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours: %w", err)
+	}
+	out := make(map[uuid.UUID]time.Time)
+	for _, q := range list {
+		if until, ok := q.Until(now); ok {
+			out[q.UserID] = until
+		}
+	}
+	return out, nil
+}
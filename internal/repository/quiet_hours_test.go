@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 10, day, hour, minute, 0, 0, moscow) }
+	tests := []struct {
+		name  string
+		q     QuietHours
+		now   time.Time
+		until time.Time
+		quiet bool
+	}{
+		{"inside same-day window", QuietHours{Timezone: "Europe/Moscow", Start: 13 * 60, End: 14 * 60}, at(18, 13, 30), at(18, 14, 0), true},
+		{"window start is inclusive", QuietHours{Timezone: "Europe/Moscow", Start: 13 * 60, End: 14 * 60}, at(18, 13, 0), at(18, 14, 0), true},
+		{"window end is exclusive", QuietHours{Timezone: "Europe/Moscow", Start: 13 * 60, End: 14 * 60}, at(18, 14, 0), time.Time{}, false},
+		{"before same-day window", QuietHours{Timezone: "Europe/Moscow", Start: 13 * 60, End: 14 * 60}, at(18, 12, 59), time.Time{}, false},
+		{"overnight window before midnight", QuietHours{Timezone: "Europe/Moscow", Start: 22 * 60, End: 7 * 60}, at(18, 23, 0), at(19, 7, 0), true},
+		{"overnight window after midnight", QuietHours{Timezone: "Europe/Moscow", Start: 22 * 60, End: 7 * 60}, at(19, 3, 0), at(19, 7, 0), true},
+		{"outside overnight window", QuietHours{Timezone: "Europe/Moscow", Start: 22 * 60, End: 7 * 60}, at(18, 12, 0), time.Time{}, false},
+		{"now in another zone", QuietHours{Timezone: "Europe/Moscow", Start: 22 * 60, End: 7 * 60}, time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC), at(19, 7, 0), true},
+		{"empty window", QuietHours{Timezone: "Europe/Moscow", Start: 600, End: 600}, at(18, 10, 0), time.Time{}, false},
+		{"unknown timezone", QuietHours{Timezone: "Mars/Olympus", Start: 0, End: 1439}, at(18, 10, 0), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.q.Until(tt.now)
+			if quiet != tt.quiet || !until.Equal(tt.until) {
+				t.Errorf("Until(%s) = %s, %v; want %s, %v", tt.now, until, quiet, tt.until, tt.quiet)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Таблицы, к которым применяется политика хранения.
+const (
+	RetentionEvents  = "notification_events"
+	RetentionPending = "notification_pending"
+)
+
+// RetentionTables — таблицы в порядке очистки.
+var RetentionTables = []string{RetentionEvents, RetentionPending}
+
+// retentionKeep — записи, которые нельзя удалять независимо от возраста: события, ждущие дайджеста,
+// и события с недоставленными доставками внешних каналов.
+var retentionKeep = map[string]string{
+	RetentionEvents: `
+		AND (t.digest_status IS NULL OR t.digest_status <> 'pending')
+		AND NOT EXISTS (SELECT 1 FROM notification_deliveries d WHERE d.event_id = t.id AND d.status = 'pending')`,
+	RetentionPending: ``,
+}
+
+// RetentionRule — записи таблицы, созданные раньше Before, с типом события из EventTypes
+// (Others — с любым типом, кроме EventTypes).
+type RetentionRule struct {
+	EventTypes []string
+	Others     bool
+	Before     time.Time
+}
+
+// RetentionRepository удаляет устаревшие записи пачками по created_at: запросы ограничены диапазоном
+// created_at и при партиционировании таблиц по created_at затрагивают только старые партиции.
+type RetentionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRetentionRepository(pool *pgxpool.Pool) *RetentionRepository {
+	return &RetentionRepository{pool: pool}
+}
+
+func retentionWhere(table string) (string, error) {
+	keep, ok := retentionKeep[table]
+	if !ok {
+		return "", fmt.Errorf("retention: unknown table %q", table)
+	}
+	return `t.created_at < $1
+		AND (CASE WHEN $2::boolean THEN NOT (t.event_type = ANY(COALESCE($3::text[], '{}')))
+			ELSE t.event_type = ANY(COALESCE($3::text[], '{}')) END)` + keep, nil
+}
+
+// Purge удаляет до limit самых старых записей table по правилу; с archive они переносятся в notification_archive.
+// Возвращает число удалённых записей. FOR UPDATE SKIP LOCKED позволяет чистить с нескольких реплик.
+func (r *RetentionRepository) Purge(ctx context.Context, table string, rule RetentionRule, limit int, archive bool) (int64, error) {
+	where, err := retentionWhere(table)
+	if err != nil {
+		return 0, err
+	}
+	sink := `SELECT count(*) FROM deleted`
+	if archive {
+		sink = `, archived AS (
+			INSERT INTO notification_archive (source, id, event_type, user_id, created_at, data)
+			SELECT '` + table + `', id, event_type, user_id, created_at, to_jsonb(deleted) FROM deleted
+			ON CONFLICT DO NOTHING)
+		SELECT count(*) FROM deleted`
+	}
+	var n int64
+	err = r.pool.QueryRow(ctx, `
+		WITH batch AS (
+			SELECT t.id FROM `+table+` t
+			WHERE `+where+`
+			ORDER BY t.created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		), deleted AS (
+			DELETE FROM `+table+` t
+			WHERE t.id IN (SELECT id FROM batch) AND t.created_at < $1
+			RETURNING t.*
+		)`+sink,
+		rule.Before, rule.Others, rule.EventTypes, limit).Scan(&n)
+	return n, err
+}
+
+// Count возвращает, сколько записей table подпадает под правило (для --dry-run).
+func (r *RetentionRepository) Count(ctx context.Context, table string, rule RetentionRule) (int64, error) {
+	where, err := retentionWhere(table)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = r.pool.QueryRow(ctx, `SELECT count(*) FROM `+table+` t WHERE `+where,
+		rule.Before, rule.Others, rule.EventTypes).Scan(&n)
+	return n, err
+}
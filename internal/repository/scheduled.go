@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Статусы отложенных уведомлений.
+const (
+	ScheduledPending     = "pending"
+	ScheduledDispatching = "dispatching"
+	ScheduledSent        = "sent"
+	ScheduledCanceled    = "canceled"
+)
+
+// ErrNotPending — отложенное уведомление уже отправлено, отправляется или отменено.
+var ErrNotPending = errors.New("scheduled notification is not pending")
+
+// ScheduledNotification — уведомление, которое будет отправлено в DeliverAt.
+type ScheduledNotification struct {
+	ID        uuid.UUID
+	EventType string
+	// Message — конверт события в формате Kafka (event, payload, session_id, user_ids, ...).
+	Message   json.RawMessage
+	DeliverAt time.Time
+	Status    string
+	SentAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ScheduledRepository — очередь отложенных уведомлений.
+type ScheduledRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewScheduledRepository(pool *pgxpool.Pool) *ScheduledRepository {
+	return &ScheduledRepository{pool: pool}
+}
+
+const scheduledColumns = `id, event_type, message, deliver_at, status, sent_at, created_at, updated_at`
+
+func scanScheduled(row pgx.Row) (ScheduledNotification, error) {
+	var n ScheduledNotification
+	var message []byte
+	err := row.Scan(&n.ID, &n.EventType, &message, &n.DeliverAt, &n.Status, &n.SentAt, &n.CreatedAt, &n.UpdatedAt)
+	n.Message = message
+	return n, err
+}
+
+// Create сохраняет отложенное уведомление.
+func (r *ScheduledRepository) Create(ctx context.Context, eventType string, message json.RawMessage, deliverAt time.Time) (ScheduledNotification, error) {
+	return scanScheduled(r.pool.QueryRow(ctx, `
+		INSERT INTO scheduled_notifications (event_type, message, deliver_at)
+		VALUES ($1, $2, $3)
+		RETURNING `+scheduledColumns,
+		eventType, message, deliverAt))
+}
+
+// Get возвращает отложенное уведомление или ErrNotFound.
+func (r *ScheduledRepository) Get(ctx context.Context, id uuid.UUID) (ScheduledNotification, error) {
+	n, err := scanScheduled(r.pool.QueryRow(ctx, `
+		SELECT `+scheduledColumns+` FROM scheduled_notifications WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return n, ErrNotFound
+	}
+	return n, err
+}
+
+// Cancel отменяет ещё не отправленное уведомление: ErrNotFound, если его нет,
+// ErrNotPending, если оно уже отправлено, отправляется или отменено.
+func (r *ScheduledRepository) Cancel(ctx context.Context, id uuid.UUID) (ScheduledNotification, error) {
+	n, err := scanScheduled(r.pool.QueryRow(ctx, `
+		UPDATE scheduled_notifications
+		SET status = 'canceled', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+scheduledColumns, id))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return n, err
+	}
+	if _, err := r.Get(ctx, id); err != nil {
+		return n, err
+	}
+	return n, ErrNotPending
+}
+
+// ClaimDue забирает до limit уведомлений, срок которых наступил, и помечает их dispatching на lease.
+// FOR UPDATE SKIP LOCKED не даёт двум репликам забрать одно уведомление; если процесс упадёт
+// до MarkSent, уведомление вернётся в очередь после истечения lease.
+func (r *ScheduledRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]ScheduledNotification, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM scheduled_notifications
+			WHERE deliver_at <= CURRENT_TIMESTAMP
+			  AND (status = 'pending' OR (status = 'dispatching' AND claimed_until < CURRENT_TIMESTAMP))
+			ORDER BY deliver_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE scheduled_notifications s
+		SET status = 'dispatching',
+		    claimed_until = CURRENT_TIMESTAMP + make_interval(secs => $2),
+		    updated_at = CURRENT_TIMESTAMP
+		FROM due
+		WHERE s.id = due.id
+		RETURNING s.id, s.event_type, s.message, s.deliver_at, s.status, s.sent_at, s.created_at, s.updated_at`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ScheduledNotification, error) {
+		return scanScheduled(row)
+	})
+}
+
+// MarkSent отмечает уведомление отправленным.
+func (r *ScheduledRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_notifications
+		SET status = 'sent', sent_at = CURRENT_TIMESTAMP, claimed_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	return err
+}
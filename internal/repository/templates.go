@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Template — шаблон заголовка и текста уведомления для типа события и языка.
+type Template struct {
+	EventType string
+	Locale    string
+	Title     string
+	Body      string
+	UpdatedAt time.Time
+}
+
+// TemplateRepository хранит шаблоны уведомлений.
+type TemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTemplateRepository(pool *pgxpool.Pool) *TemplateRepository {
+	return &TemplateRepository{pool: pool}
+}
+
+const templateColumns = `event_type, locale, title, body, updated_at`
+
+func scanTemplate(row pgx.Row) (Template, error) {
+	var t Template
+	err := row.Scan(&t.EventType, &t.Locale, &t.Title, &t.Body, &t.UpdatedAt)
+	return t, err
+}
+
+// Set сохраняет (или заменяет) шаблон.
+func (r *TemplateRepository) Set(ctx context.Context, t Template) (Template, error) {
+	return scanTemplate(r.pool.QueryRow(ctx, `
+		INSERT INTO notification_templates (event_type, locale, title, body, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (event_type, locale) DO UPDATE
+		SET title = EXCLUDED.title, body = EXCLUDED.body, updated_at = CURRENT_TIMESTAMP
+		RETURNING `+templateColumns,
+		t.EventType, t.Locale, t.Title, t.Body))
+}
+
+// List возвращает все шаблоны.
+func (r *TemplateRepository) List(ctx context.Context) ([]Template, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+templateColumns+` FROM notification_templates ORDER BY event_type, locale`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Template, error) {
+		return scanTemplate(row)
+	})
+}
+
+// Delete удаляет шаблон; ErrNotFound, если его нет.
+func (r *TemplateRepository) Delete(ctx context.Context, eventType, locale string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM notification_templates WHERE event_type = $1 AND locale = $2`, eventType, locale)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Webhook — подписка партнёрской системы на события.
+type Webhook struct {
+	ID        uuid.UUID
+	URL       string
+	Events    []string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery — запись журнала доставок webhook.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	ResponseStatus *int
+	LastError      *string
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// WebhookRepository — реестр webhook-подписок и журнал их доставок.
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+const webhookColumns = `id, url, events, secret, active, created_at, updated_at`
+
+// webhookDeliveryColumns — колонки webhook_deliveries с алиасом d (для UPDATE ... FROM и SELECT).
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.next_attempt_at, d.delivered_at, d.created_at`
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Events, &w.Secret, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return w, ErrNotFound
+	}
+	return w, err
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, w Webhook) (Webhook, error) {
+	return scanWebhook(r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, events, secret, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		w.URL, w.Events, w.Secret, w.Active))
+}
+
+func (r *WebhookRepository) Get(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	return scanWebhook(r.pool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+}
+
+// List возвращает все подписки; activeOnly — только активные.
+func (r *WebhookRepository) List(ctx context.Context, activeOnly bool) ([]Webhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookColumns+` FROM webhook_subscriptions
+		WHERE active OR NOT $1
+		ORDER BY created_at`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Webhook, error) { return scanWebhook(row) })
+}
+
+// Update заменяет url, events, active (если не nil) и (если secret не пуст) секрет подписки.
+func (r *WebhookRepository) Update(ctx context.Context, w Webhook, active *bool) (Webhook, error) {
+	return scanWebhook(r.pool.QueryRow(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2, events = $3, active = COALESCE($4, active),
+		    secret = COALESCE(NULLIF($5, ''), secret),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		w.ID, w.URL, w.Events, active, w.Secret))
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries ставит событие в очередь доставки для каждой подписки.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, subscriptionIDs []uuid.UUID, eventType string, payload json.RawMessage) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+		SELECT unnest($1::uuid[]), $2::varchar, $3::jsonb`,
+		subscriptionIDs, eventType, string(payload))
+	return err
+}
+
+// ClaimDue забирает доставки, срок которых наступил, и откладывает их на lease (см. DeliveryRepository.ClaimDue).
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2),
+		    updated_at = CURRENT_TIMESTAMP
+		FROM due
+		WHERE d.id = due.id
+		RETURNING `+webhookDeliveryColumns,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanWebhookDelivery)
+}
+
+// Defer переносит доставку без учёта попытки (например, пока circuit breaker открыт).
+func (r *WebhookRepository) Defer(ctx context.Context, id uuid.UUID, until time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = GREATEST(attempts - 1, 0), next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, until)
+	return err
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', response_status = $2, last_error = NULL,
+		    delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, responseStatus)
+	return err
+}
+
+// MarkRetry сохраняет результат неуспешной попытки и планирует следующую; responseStatus = 0 — ответа не было.
+func (r *WebhookRepository) MarkRetry(ctx context.Context, id uuid.UUID, responseStatus int, lastErr string, nextAttempt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET response_status = NULLIF($2, 0), last_error = $3, next_attempt_at = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, responseStatus, lastErr, nextAttempt)
+	return err
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id uuid.UUID, responseStatus int, lastErr string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'failed', response_status = NULLIF($2, 0), last_error = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, responseStatus, lastErr)
+	return err
+}
+
+// ListDeliveries возвращает журнал доставок подписки от новых к старым (keyset-пагинация по Cursor).
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, after *Cursor, limit int) ([]WebhookDelivery, error) {
+	var afterTime *time.Time
+	var afterID uuid.UUID
+	if after != nil {
+		afterTime, afterID = &after.CreatedAt, after.ID
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		  AND ($2::timestamptz IS NULL OR (d.created_at, d.id) < ($2, $3))
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $4`,
+		subscriptionID, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanWebhookDelivery)
+}
+
+func scanWebhookDelivery(row pgx.CollectableRow) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	var payload []byte
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt)
+	d.Payload = payload
+	return d, err
+}
@@ -0,0 +1,107 @@
+package retention
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// Keep — срок хранения «бессрочно».
+const Keep time.Duration = 0
+
+// Policy — срок хранения записей по типам событий; Keep — не удалять.
+type Policy struct {
+	Default time.Duration
+	Events  map[string]time.Duration
+}
+
+// ParsePolicy разбирает строку вида "*:90d,psds.session.created:30d,psds.operator.assigned:keep".
+// Срок — длительность Go (720h) или число дней (30d); keep или 0 — хранить бессрочно.
+// "*" задаёт срок для событий, не перечисленных явно; без него они хранятся бессрочно.
+func ParsePolicy(s string) (Policy, error) {
+	p := Policy{Default: Keep, Events: make(map[string]time.Duration)}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		event, raw, ok := strings.Cut(item, ":")
+		if !ok {
+			return p, fmt.Errorf("retention %q: expected event:ttl", item)
+		}
+		ttl, err := parseTTL(strings.TrimSpace(raw))
+		if err != nil {
+			return p, fmt.Errorf("retention %q: %w", item, err)
+		}
+		if event = strings.TrimSpace(event); event == "*" {
+			p.Default = ttl
+		} else {
+			p.Events[event] = ttl
+		}
+	}
+	return p, nil
+}
+
+func parseTTL(s string) (time.Duration, error) {
+	switch {
+	case s == "keep" || s == "0":
+		return Keep, nil
+	case strings.HasSuffix(s, "d"):
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid ttl %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid ttl %q", s)
+	}
+	return d, nil
+}
+
+// Empty — политика ничего не удаляет.
+func (p Policy) Empty() bool {
+	if p.Default != Keep {
+		return false
+	}
+	for _, ttl := range p.Events {
+		if ttl != Keep {
+			return false
+		}
+	}
+	return true
+}
+
+// Rules переводит политику в правила очистки на момент now: по одному на срок хранения
+// перечисленных событий и одно для остальных (если задан "*").
+func (p Policy) Rules(now time.Time) []repository.RetentionRule {
+	byTTL := make(map[time.Duration][]string)
+	listed := make([]string, 0, len(p.Events))
+	for event, ttl := range p.Events {
+		listed = append(listed, event)
+		if ttl != Keep {
+			byTTL[ttl] = append(byTTL[ttl], event)
+		}
+	}
+	slices.Sort(listed)
+	ttls := make([]time.Duration, 0, len(byTTL))
+	for ttl := range byTTL {
+		ttls = append(ttls, ttl)
+	}
+	slices.Sort(ttls)
+	rules := make([]repository.RetentionRule, 0, len(ttls)+1)
+	for _, ttl := range ttls {
+		events := byTTL[ttl]
+		slices.Sort(events)
+		rules = append(rules, repository.RetentionRule{EventTypes: events, Before: now.Add(-ttl)})
+	}
+	if p.Default != Keep {
+		rules = append(rules, repository.RetentionRule{EventTypes: listed, Others: true, Before: now.Add(-p.Default)})
+	}
+	return rules
+}
@@ -0,0 +1,121 @@
+// Package retention — политика хранения уведомлений и очистка notification_events и notification_pending.
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/metrics"
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// Config — параметры очистки.
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+	// Archive — переносить удалённые записи в notification_archive.
+	Archive bool
+	// Pause — пауза между пачками, чтобы очистка не забирала всю нагрузку на БД.
+	Pause time.Duration
+}
+
+// Purger удаляет записи старше срока хранения пачками по BatchSize.
+type Purger struct {
+	repo   *repository.RetentionRepository
+	policy Policy
+	cfg    Config
+}
+
+func NewPurger(repo *repository.RetentionRepository, policy Policy, cfg Config) *Purger {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	return &Purger{repo: repo, policy: policy, cfg: cfg}
+}
+
+// Result — число удалённых (или найденных при dry run) записей по таблицам.
+type Result map[string]int64
+
+// Run очищает таблицы раз в Interval до отмены ctx.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		res, err := p.Purge(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "retention: purge", "error", err)
+			}
+		} else {
+			slog.InfoContext(ctx, "retention: purged", "events", res[repository.RetentionEvents], "pending", res[repository.RetentionPending], "archive", p.cfg.Archive)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge выполняет один проход очистки по всем таблицам и правилам политики.
+func (p *Purger) Purge(ctx context.Context) (Result, error) {
+	res := make(Result)
+	rules := p.policy.Rules(time.Now())
+	action := "deleted"
+	if p.cfg.Archive {
+		action = "archived"
+	}
+	for _, table := range repository.RetentionTables {
+		for _, rule := range rules {
+			for {
+				n, err := p.repo.Purge(ctx, table, rule, p.cfg.BatchSize, p.cfg.Archive)
+				if err != nil {
+					return res, err
+				}
+				res[table] += n
+				metrics.RetentionPurged.WithLabelValues(table, action).Add(float64(n))
+				if n < int64(p.cfg.BatchSize) {
+					break
+				}
+				if err := sleep(ctx, p.cfg.Pause); err != nil {
+					return res, err
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// DryRun считает записи, которые удалил бы Purge, ничего не удаляя.
+func (p *Purger) DryRun(ctx context.Context) (Result, error) {
+	res := make(Result)
+	rules := p.policy.Rules(time.Now())
+	for _, table := range repository.RetentionTables {
+		for _, rule := range rules {
+			n, err := p.repo.Count(ctx, table, rule)
+			if err != nil {
+				return res, err
+			}
+			res[table] += n
+		}
+	}
+	return res, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
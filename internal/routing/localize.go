@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/internal/templates"
+	"github.com/psds-microservice/notification-service/internal/tracing"
+)
+
+// Localizer рендерит заголовок и текст события по шаблону на языке получателя.
+type Localizer interface {
+	Has(event string) bool
+	Locales(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error)
+	Render(event, locale string, data templates.Data) (templates.Rendered, bool)
+}
+
+// wsSender рассылает событие по WebSocket. Если событие сохранено в историю, кадр получает
+// notification_id; в контексте трассировки — trace_id. Если для события есть шаблон, в кадр
+// добавляются title, body и locale на языке получателя. Язык берётся из метаданных подключения,
+// иначе из настроек пользователя. Кадры строятся один раз на язык.
+type wsSender struct {
+	ctx       context.Context
+	hub       *service.NotifyHub
+	raw       []byte
+	localizer Localizer
+	msg       Message
+	data      templates.Data
+	locales   map[uuid.UUID]string
+	frames    map[string][]byte
+}
+
+func (r *Router) newWSSender(ctx context.Context, msg Message, raw []byte, recipients []uuid.UUID, notificationID uuid.UUID) *wsSender {
+	s := &wsSender{ctx: ctx, hub: r.hub, raw: raw}
+	add := make(map[string]string)
+	if notificationID != uuid.Nil {
+		add["notification_id"] = notificationID.String()
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		add["trace_id"] = traceID
+	}
+	if len(add) > 0 {
+		s.raw = withFields(raw, add)
+	}
+	if r.localizer == nil || len(recipients) == 0 || !r.localizer.Has(msg.Event) {
+		return s
+	}
+	s.localizer, s.msg = r.localizer, msg
+	s.data = templates.Data{Event: msg.Event, SessionID: msg.SessionID, CreatedAt: time.Now()}
+	if len(msg.Payload) > 0 {
+		_ = json.Unmarshal(msg.Payload, &s.data.Payload)
+	}
+	s.frames = make(map[string][]byte)
+
+	var withoutLocale []uuid.UUID
+	s.locales = make(map[uuid.UUID]string, len(recipients))
+	for _, uid := range recipients {
+		if l := r.hub.LocaleOf(uid); l != "" {
+			s.locales[uid] = l
+		} else {
+			withoutLocale = append(withoutLocale, uid)
+		}
+	}
+	if len(withoutLocale) > 0 {
+		stored, err := r.localizer.Locales(ctx, withoutLocale)
+		if err != nil {
+			slog.ErrorContext(ctx, "routing: locales", "error", err)
+		}
+		for uid, l := range stored {
+			s.locales[uid] = l
+		}
+	}
+	return s
+}
+
+// send рассылает событие пользователям; target — тип адресата (service.TargetSession, ...).
+func (s *wsSender) send(target string, userIDs []uuid.UUID) {
+	if s.localizer == nil {
+		s.hub.BroadcastTo(s.ctx, target, userIDs, s.raw)
+		return
+	}
+	for _, uid := range userIDs {
+		s.hub.SendTo(s.ctx, target, uid, s.frame(s.locales[uid]))
+	}
+}
+
+// frame возвращает кадр для языка; если шаблон не отрендерился, — исходное сообщение.
+func (s *wsSender) frame(locale string) []byte {
+	if f, ok := s.frames[locale]; ok {
+		return f
+	}
+	f := s.raw
+	if rendered, ok := s.localizer.Render(s.msg.Event, locale, s.data); ok {
+		f = withFields(s.raw, map[string]string{"title": rendered.Title, "body": rendered.Body, "locale": rendered.Locale})
+	}
+	s.frames[locale] = f
+	return f
+}
+
+// withFields добавляет строковые поля в JSON-объект; если raw не объект, возвращает его как есть.
+func withFields(raw []byte, add map[string]string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return raw
+	}
+	for k, v := range add {
+		fields[k], _ = json.Marshal(v)
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return b
+}
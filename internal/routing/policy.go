@@ -0,0 +1,63 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode определяет, когда событие доставляется через внешний канал (email и т.п.).
+type Mode string
+
+const (
+	// ModeNever — не доставлять через канал.
+	ModeNever Mode = "never"
+	// ModeOffline — только если у получателя нет активного WebSocket-подключения.
+	ModeOffline Mode = "offline"
+	// ModeAlways — всегда, независимо от WebSocket.
+	ModeAlways Mode = "always"
+)
+
+// ChannelPolicy — режим доставки канала по типам событий.
+type ChannelPolicy struct {
+	Default Mode
+	Events  map[string]Mode
+}
+
+// ParseChannelPolicy разбирает строку вида "*:offline,psds.session.ended:always".
+// "*" задаёт режим для событий, не перечисленных явно; без него — never.
+func ParseChannelPolicy(s string) (ChannelPolicy, error) {
+	p := ChannelPolicy{Default: ModeNever, Events: make(map[string]Mode)}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		event, rawMode, ok := strings.Cut(item, ":")
+		if !ok {
+			return p, fmt.Errorf("policy %q: expected event:mode", item)
+		}
+		mode := Mode(strings.TrimSpace(rawMode))
+		switch mode {
+		case ModeNever, ModeOffline, ModeAlways:
+		default:
+			return p, fmt.Errorf("policy %q: unknown mode %q", item, mode)
+		}
+		if event = strings.TrimSpace(event); event == "*" {
+			p.Default = mode
+		} else {
+			p.Events[event] = mode
+		}
+	}
+	return p, nil
+}
+
+// Mode возвращает режим канала для события.
+func (p ChannelPolicy) Mode(event string) Mode {
+	if m, ok := p.Events[event]; ok {
+		return m
+	}
+	if p.Default == "" {
+		return ModeNever
+	}
+	return p.Default
+}
@@ -0,0 +1,389 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/logging"
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/service"
+	"github.com/psds-microservice/notification-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Message — конверт события (Kafka и другие источники), по которому определяются получатели.
+type Message struct {
+	// Общий конверт события, если продюсер его использует.
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Priority — low, normal (по умолчанию), high или critical; critical доставляется и в окно тишины.
+	Priority string `json:"priority,omitempty"`
+
+	// Базовые поля совместимы с предыдущей версией.
+	SessionID string   `json:"session_id,omitempty"`
+	UserID    string   `json:"user_id,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+
+	// Для agent routing по операторам/агентам.
+	OperatorID  string   `json:"operator_id,omitempty"`
+	OperatorIDs []string `json:"operator_ids,omitempty"`
+
+	// Для маршрутизации по регионам и ролям (если клиенты подключаются с этими атрибутами).
+	Regions []string `json:"regions,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// Приоритеты событий.
+const (
+	PriorityLow      = "low"
+	PriorityNormal   = "normal"
+	PriorityHigh     = "high"
+	PriorityCritical = "critical"
+)
+
+// EventLoadtest — событие нагрузочного теста (команда loadtest): доставляется только по WebSocket,
+// без настроек, истории, шаблонов, внешних каналов и webhooks, чтобы тест измерял NotifyHub, а не базу.
+const EventLoadtest = "loadtest.broadcast"
+
+// ParseMessage разбирает конверт события.
+func ParseMessage(data []byte) (Message, error) {
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("cannot unmarshal message: %w", err)
+	}
+	return m, nil
+}
+
+// Session возвращает session_id события, если он задан и корректен.
+func (m Message) Session() (uuid.UUID, bool) {
+	if m.SessionID == "" {
+		return uuid.Nil, false
+	}
+	sid, err := uuid.Parse(strings.TrimSpace(m.SessionID))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return sid, true
+}
+
+// DirectTargets возвращает прямых получателей по user_id / user_ids / operator_id / operator_ids.
+func (m Message) DirectTargets() []uuid.UUID {
+	var targets []uuid.UUID
+	appendID := func(idStr string) {
+		if idStr == "" {
+			return
+		}
+		if uid, err := uuid.Parse(strings.TrimSpace(idStr)); err == nil {
+			targets = append(targets, uid)
+		}
+	}
+	appendID(m.UserID)
+	for _, id := range m.UserIDs {
+		appendID(id)
+	}
+	appendID(m.OperatorID)
+	for _, id := range m.OperatorIDs {
+		appendID(id)
+	}
+	return targets
+}
+
+// Critical сообщает, что событие имеет приоритет critical.
+func (m Message) Critical() bool {
+	return strings.EqualFold(strings.TrimSpace(m.Priority), PriorityCritical)
+}
+
+// Outbox ставит событие notificationID в очередь доставки внешнего канала; notBefore откладывает
+// доставку (нулевое значение — сразу).
+type Outbox interface {
+	Enqueue(ctx context.Context, channel string, notificationID uuid.UUID, msg Message, userIDs []uuid.UUID, notBefore time.Time) error
+}
+
+// WebhookPublisher ставит событие в очередь для подходящих webhook-подписок.
+type WebhookPublisher interface {
+	Publish(ctx context.Context, msg Message, raw []byte) error
+}
+
+// PreferenceResolver возвращает действующие настройки уведомлений получателей события.
+type PreferenceResolver interface {
+	Resolve(ctx context.Context, userIDs []uuid.UUID, eventType string) (map[uuid.UUID]repository.Preference, error)
+}
+
+// QuietHoursResolver возвращает для пользователей в окне тишины момент его окончания.
+type QuietHoursResolver interface {
+	QuietUntil(ctx context.Context, userIDs []uuid.UUID, now time.Time) (map[uuid.UUID]time.Time, error)
+}
+
+// DigestCollector откладывает событие в дайджест получателей, у которых оно подпадает под настройку
+// дайджеста, и возвращает их: по отдельности событие им не доставляется.
+type DigestCollector interface {
+	Collect(ctx context.Context, msg Message, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// HistoryRecorder сохраняет событие notificationID в историю уведомлений получателей.
+type HistoryRecorder interface {
+	Record(ctx context.Context, notificationID uuid.UUID, msg Message, userIDs []uuid.UUID) error
+}
+
+// Options — внешние каналы маршрутизатора: Outbox и политика для каждого канала, webhook-подписки,
+// настройки пользователей (без них доставка разрешена во все каналы), окна тишины, дайджесты
+// и история уведомлений.
+type Options struct {
+	Outbox      Outbox
+	Channels    map[string]ChannelPolicy
+	Webhooks    WebhookPublisher
+	Preferences PreferenceResolver
+	QuietHours  QuietHoursResolver
+	Digests     DigestCollector
+	Localizer   Localizer
+	History     HistoryRecorder
+}
+
+// Router — общий конвейер маршрутизации: WebSocket через NotifyHub и внешние каналы через Outbox.
+type Router struct {
+	hub         *service.NotifyHub
+	outbox      Outbox
+	channels    map[string]ChannelPolicy
+	webhooks    WebhookPublisher
+	preferences PreferenceResolver
+	quietHours  QuietHoursResolver
+	digests     DigestCollector
+	localizer   Localizer
+	history     HistoryRecorder
+}
+
+func NewRouter(hub *service.NotifyHub, opts Options) *Router {
+	return &Router{
+		hub:         hub,
+		outbox:      opts.Outbox,
+		channels:    opts.Channels,
+		webhooks:    opts.Webhooks,
+		preferences: opts.Preferences,
+		quietHours:  opts.QuietHours,
+		digests:     opts.Digests,
+		localizer:   opts.Localizer,
+		history:     opts.History,
+	}
+}
+
+// recipients — получатели одного события: их настройки и те, кому событие уйдёт в дайджест.
+type recipients struct {
+	prefs    map[uuid.UUID]repository.Preference
+	digested map[uuid.UUID]bool
+}
+
+// filter оставляет пользователей, разрешивших доставку через канал и не получающих событие в дайджесте.
+func (rc recipients) filter(userIDs []uuid.UUID, channel string) []uuid.UUID {
+	if len(rc.prefs) == 0 && len(rc.digested) == 0 {
+		return userIDs
+	}
+	out := make([]uuid.UUID, 0, len(userIDs))
+	for _, uid := range userIDs {
+		if rc.digested[uid] {
+			continue
+		}
+		if pref, ok := rc.prefs[uid]; !ok || pref.Allows(channel) {
+			out = append(out, uid)
+		}
+	}
+	return out
+}
+
+// uniqueUsers объединяет группы пользователей без повторов.
+func uniqueUsers(groups ...[]uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{})
+	var userIDs []uuid.UUID
+	for _, group := range groups {
+		for _, uid := range group {
+			if _, ok := seen[uid]; !ok {
+				seen[uid] = struct{}{}
+				userIDs = append(userIDs, uid)
+			}
+		}
+	}
+	return userIDs
+}
+
+// resolveRecipients загружает настройки всех получателей события и откладывает событие в дайджесты.
+// При ошибке хранилища доставка не блокируется: событие уходит так, как если бы настроек не было.
+func (r *Router) resolveRecipients(ctx context.Context, msg Message, userIDs []uuid.UUID) recipients {
+	var rc recipients
+	if len(userIDs) == 0 {
+		return rc
+	}
+	if r.preferences != nil {
+		prefs, err := r.preferences.Resolve(ctx, userIDs, msg.Event)
+		if err != nil {
+			slog.ErrorContext(ctx, "routing: preferences", "error", err)
+		}
+		rc.prefs = prefs
+	}
+	if r.digests != nil {
+		// Пользователь, отключивший событие, не получает его и в дайджесте.
+		candidates := make([]uuid.UUID, 0, len(userIDs))
+		for _, uid := range userIDs {
+			if pref, ok := rc.prefs[uid]; !ok || !pref.Muted {
+				candidates = append(candidates, uid)
+			}
+		}
+		digested, err := r.digests.Collect(ctx, msg, candidates)
+		if err != nil {
+			slog.ErrorContext(ctx, "routing: digests", "error", err)
+		}
+		rc.digested = digested
+	}
+	return rc
+}
+
+// Route разбирает сообщение и доставляет его получателям. Для WebSocket-клиента
+// пересылается оригинальное тело сообщения как есть.
+func (r *Router) Route(ctx context.Context, raw []byte) error {
+	msg, err := ParseMessage(raw)
+	if err != nil {
+		return err
+	}
+	ctx, span := tracing.Tracer.Start(ctx, "route "+msg.Event, trace.WithAttributes(
+		attribute.String("event", msg.Event),
+		attribute.String("session_id", msg.SessionID),
+		attribute.String("priority", msg.Priority),
+	))
+	defer span.End()
+	ctx = logging.With(ctx, "event", msg.Event)
+	if msg.SessionID != "" {
+		ctx = logging.With(ctx, "session_id", msg.SessionID)
+	}
+
+	// Получатели WebSocket: подписчики сессии, прямые получатели, регионы и роли.
+	var sessionUsers, regionUsers, roleUsers []uuid.UUID
+	if sid, ok := msg.Session(); ok {
+		sessionUsers = r.hub.SessionSubscribers(sid)
+	}
+	directTargets := msg.DirectTargets()
+	if len(msg.Regions) > 0 {
+		regionUsers = r.hub.UsersInRegions(msg.Regions)
+	}
+	if len(msg.Roles) > 0 {
+		roleUsers = r.hub.UsersWithRoles(msg.Roles)
+	}
+	if msg.Event == EventLoadtest {
+		r.hub.BroadcastTo(ctx, service.TargetSession, sessionUsers, raw)
+		r.hub.BroadcastTo(ctx, service.TargetUser, directTargets, raw)
+		r.hub.BroadcastTo(ctx, service.TargetRegion, regionUsers, raw)
+		r.hub.BroadcastTo(ctx, service.TargetRole, roleUsers, raw)
+		return nil
+	}
+	allUsers := uniqueUsers(sessionUsers, directTargets, regionUsers, roleUsers)
+	rc := r.resolveRecipients(ctx, msg, allUsers)
+	// notification_id события общий для истории и доставок внешних каналов; в кадр WebSocket он
+	// попадает, только если событие сохранено в историю.
+	notificationID := uuid.New()
+	frameID := uuid.Nil
+	if r.recordHistory(ctx, notificationID, msg, allUsers, rc) {
+		frameID = notificationID
+	}
+	sessionUsers = rc.filter(sessionUsers, repository.ChannelWebSocket)
+	regionUsers = rc.filter(regionUsers, repository.ChannelWebSocket)
+	roleUsers = rc.filter(roleUsers, repository.ChannelWebSocket)
+	directWS := rc.filter(directTargets, repository.ChannelWebSocket)
+	wsUsers := uniqueUsers(sessionUsers, directWS, regionUsers, roleUsers)
+	span.SetAttributes(attribute.Int("recipients.websocket", len(wsUsers)), attribute.Int("recipients.direct", len(directTargets)))
+	ws := r.newWSSender(ctx, msg, raw, wsUsers, frameID)
+
+	// 1. Рассылка по session_id.
+	ws.send(service.TargetSession, sessionUsers)
+
+	// 2. Прямые получатели.
+	if len(directTargets) > 0 {
+		ws.send(service.TargetUser, directWS)
+		r.enqueueExternal(ctx, notificationID, msg, directTargets, rc)
+	}
+
+	// 3. Маршрутизация по регионам и ролям (если клиенты передают эти атрибуты при подключении).
+	ws.send(service.TargetRegion, regionUsers)
+	ws.send(service.TargetRole, roleUsers)
+
+	// 4. Webhook-подписки партнёров (по типу события). Если у события есть прямые
+	// получатели и все они отключили канал webhook, событие партнёрам не отправляется.
+	if r.webhooks != nil && (len(directTargets) == 0 || len(recipients{prefs: rc.prefs}.filter(directTargets, repository.ChannelWebhook)) > 0) {
+		if err := r.webhooks.Publish(ctx, msg, raw); err != nil {
+			slog.ErrorContext(ctx, "routing: webhooks", "error", err)
+		}
+	}
+	return nil
+}
+
+// recordHistory сохраняет событие в историю всех получателей, кроме отключивших его; false — история
+// не ведётся или сохранить не удалось.
+func (r *Router) recordHistory(ctx context.Context, notificationID uuid.UUID, msg Message, userIDs []uuid.UUID, rc recipients) bool {
+	if r.history == nil || len(userIDs) == 0 {
+		return false
+	}
+	targets := make([]uuid.UUID, 0, len(userIDs))
+	for _, uid := range userIDs {
+		if pref, ok := rc.prefs[uid]; !ok || !pref.Muted {
+			targets = append(targets, uid)
+		}
+	}
+	if len(targets) == 0 {
+		return false
+	}
+	if err := r.history.Record(ctx, notificationID, msg, targets); err != nil {
+		slog.ErrorContext(ctx, "routing: history", "error", err)
+		return false
+	}
+	return true
+}
+
+// enqueueExternal ставит доставку прямым получателям во внешние каналы согласно политикам каналов
+// и настройкам получателей. Доставка пользователям в окне тишины откладывается до его окончания,
+// если событие не critical.
+func (r *Router) enqueueExternal(ctx context.Context, notificationID uuid.UUID, msg Message, userIDs []uuid.UUID, rc recipients) {
+	if r.outbox == nil || len(r.channels) == 0 {
+		return
+	}
+	quiet := r.resolveQuietHours(ctx, msg, userIDs)
+	for channel, policy := range r.channels {
+		allowed := rc.filter(userIDs, channel)
+		var targets []uuid.UUID
+		switch policy.Mode(msg.Event) {
+		case ModeAlways:
+			targets = allowed
+		case ModeOffline:
+			for _, uid := range allowed {
+				if !r.hub.IsOnline(uid) {
+					targets = append(targets, uid)
+				}
+			}
+		}
+		// Группируем получателей по моменту доставки: сразу или по окончании окна тишины.
+		groups := make(map[time.Time][]uuid.UUID)
+		for _, uid := range targets {
+			groups[quiet[uid]] = append(groups[quiet[uid]], uid)
+		}
+		for notBefore, group := range groups {
+			if err := r.outbox.Enqueue(ctx, channel, notificationID, msg, group, notBefore); err != nil {
+				slog.ErrorContext(ctx, "routing: enqueue", "channel", channel, "error", err)
+			}
+		}
+	}
+}
+
+// resolveQuietHours возвращает окончание окна тишины для получателей, которым доставку нужно отложить.
+// При ошибке хранилища события доставляются сразу.
+func (r *Router) resolveQuietHours(ctx context.Context, msg Message, userIDs []uuid.UUID) map[uuid.UUID]time.Time {
+	if r.quietHours == nil || msg.Critical() {
+		return nil
+	}
+	quiet, err := r.quietHours.QuietUntil(ctx, userIDs, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "routing: quiet hours", "error", err)
+		return nil
+	}
+	return quiet
+}
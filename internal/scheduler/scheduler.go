@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/psds-microservice/notification-service/internal/repository"
+	"github.com/psds-microservice/notification-service/internal/routing"
+)
+
+// Config — параметры цикла отложенных уведомлений.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease — на сколько уведомление закрепляется за репликой, забравшей его из очереди.
+	Lease time.Duration
+}
+
+// Scheduler отправляет наступившие отложенные уведомления через общий конвейер маршрутизации
+// (NotifyHub и внешние каналы), как если бы событие пришло из Kafka.
+type Scheduler struct {
+	repo   *repository.ScheduledRepository
+	router *routing.Router
+	cfg    Config
+}
+
+func New(repo *repository.ScheduledRepository, router *routing.Router, cfg Config) *Scheduler {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	return &Scheduler{repo: repo, router: router, cfg: cfg}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			batch, err := s.repo.ClaimDue(ctx, s.cfg.BatchSize, s.cfg.Lease)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "scheduler: claim", "error", err)
+				}
+				break
+			}
+			for _, n := range batch {
+				s.dispatch(ctx, n)
+			}
+			if len(batch) < s.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) dispatch(ctx context.Context, n repository.ScheduledNotification) {
+	// Ошибка Route — только неразборчивое сообщение: повтор не поможет, поэтому уведомление всё равно закрывается.
+	if err := s.router.Route(ctx, n.Message); err != nil {
+		slog.ErrorContext(ctx, "scheduler: route", "scheduled_id", n.ID, "error", err)
+	}
+	if err := s.repo.MarkSent(ctx, n.ID); err != nil {
+		slog.ErrorContext(ctx, "scheduler: mark sent", "scheduled_id", n.ID, "error", err)
+	}
+}
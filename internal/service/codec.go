@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/notification-service/pkg/gen/notification_service"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// WebSocket-подпротоколы, согласуемые при upgrade (заголовок Sec-WebSocket-Protocol).
+const (
+	SubprotocolJSON  = "notify.v1.json"
+	SubprotocolProto = "notify.v1.proto"
+)
+
+// Subprotocols — поддерживаемые подпротоколы; JSON используется, если клиент ничего не запросил.
+var Subprotocols = []string{SubprotocolJSON, SubprotocolProto}
+
+// Типы Envelope, которые формирует сервер помимо ответов на запросы.
+const (
+	// EnvelopeTypeEvent — уведомление (сообщение без собственного поля type).
+	EnvelopeTypeEvent = "event"
+	// EnvelopeTypeBatch — пачка сообщений в режиме coalescing, сами сообщения в items.
+	EnvelopeTypeBatch = "batch"
+)
+
+// FrameCodec преобразует JSON-сообщения хаба в кадры WebSocket и обратно.
+type FrameCodec interface {
+	// Encode возвращает тип кадра (websocket.TextMessage/BinaryMessage) и его содержимое.
+	Encode(msg []byte) (int, []byte, error)
+	// EncodeBatch упаковывает несколько сообщений в один кадр.
+	EncodeBatch(msgs [][]byte) (int, []byte, error)
+	// Decode приводит входящий кадр клиента к JSON для ParseClientRequest.
+	Decode(messageType int, data []byte) ([]byte, error)
+}
+
+// CodecFor возвращает кодек для согласованного подпротокола.
+func CodecFor(subprotocol string) FrameCodec {
+	if subprotocol == SubprotocolProto {
+		return protoCodec{}
+	}
+	return jsonCodec{}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(msg []byte) (int, []byte, error) {
+	return websocket.TextMessage, msg, nil
+}
+
+// EncodeBatch собирает JSON-массив из уже сериализованных сообщений без повторного разбора.
+func (jsonCodec) EncodeBatch(msgs [][]byte) (int, []byte, error) {
+	size := 2 + len(msgs)
+	for _, m := range msgs {
+		size += len(m)
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, '[')
+	for i, m := range msgs {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, m...)
+	}
+	buf = append(buf, ']')
+	return websocket.TextMessage, buf, nil
+}
+
+func (jsonCodec) Decode(_ int, data []byte) ([]byte, error) {
+	return data, nil
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Encode(msg []byte) (int, []byte, error) {
+	env, err := envelopeFromJSON(msg)
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+func (protoCodec) EncodeBatch(msgs [][]byte) (int, []byte, error) {
+	batch := &notification_service.Envelope{Type: EnvelopeTypeBatch, Items: make([]*notification_service.Envelope, 0, len(msgs))}
+	for _, msg := range msgs {
+		env, err := envelopeFromJSON(msg)
+		if err != nil {
+			return 0, nil, err
+		}
+		batch.Items = append(batch.Items, env)
+	}
+	data, err := proto.Marshal(batch)
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+func (protoCodec) Decode(messageType int, data []byte) ([]byte, error) {
+	if messageType != websocket.BinaryMessage {
+		return nil, errors.New("notify.v1.proto expects binary frames")
+	}
+	var env notification_service.Envelope
+	if err := proto.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	m := env.GetBody().AsMap()
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	if env.GetType() != "" {
+		m["type"] = env.GetType()
+	}
+	if env.GetRequestId() != "" {
+		m["request_id"] = env.GetRequestId()
+	}
+	return json.Marshal(m)
+}
+
+// envelopeFromJSON упаковывает JSON-объект в Envelope, вынося type/request_id/event/trace_id в отдельные поля.
+func envelopeFromJSON(msg []byte) (*notification_service.Envelope, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	body, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil, err
+	}
+	env := &notification_service.Envelope{Type: EnvelopeTypeEvent, Body: body}
+	if v, ok := m["type"].(string); ok && v != "" {
+		env.Type = v
+	}
+	if v, ok := m["request_id"].(string); ok {
+		env.RequestId = v
+	}
+	if v, ok := m["event"].(string); ok {
+		env.Event = v
+	}
+	if v, ok := m["trace_id"].(string); ok {
+		env.TraceId = v
+	}
+	return env, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ConnectionInfo — снимок подключения для admin API.
+type ConnectionInfo struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Meta        ClientMetadata
+	Sessions    []uuid.UUID
+	QueueDepth  int
+	QueueSize   int
+	ConnectedAt time.Time
+}
+
+// Connections возвращает подключения этой реплики, упорядоченные по времени подключения.
+func (h *NotifyHub) Connections() []ConnectionInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	sessions := make(map[uuid.UUID][]uuid.UUID)
+	for sid, m := range h.sessions {
+		for uid := range m {
+			sessions[uid] = append(sessions[uid], sid)
+		}
+	}
+	out := make([]ConnectionInfo, 0, len(h.users))
+	for _, c := range h.users {
+		out = append(out, ConnectionInfo{
+			ID:          c.ID,
+			UserID:      c.UserID,
+			Meta:        c.Meta,
+			Sessions:    sessions[c.UserID],
+			QueueDepth:  len(c.Send),
+			QueueSize:   cap(c.Send),
+			ConnectedAt: c.ConnectedAt,
+		})
+	}
+	slices.SortFunc(out, func(a, b ConnectionInfo) int { return a.ConnectedAt.Compare(b.ConnectedAt) })
+	return out
+}
+
+// DisconnectUser закрывает подключение пользователя: очередь дописывается, затем клиент получает
+// close-кадр 1000 с причиной reason. false — пользователь не подключён к этой реплике.
+func (h *NotifyHub) DisconnectUser(userID uuid.UUID, reason string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := h.users[userID]
+	if c == nil {
+		return false
+	}
+	h.disconnectLocked(c, reason)
+	return true
+}
+
+// DisconnectConn — DisconnectUser по идентификатору подключения (conn_id).
+func (h *NotifyHub) DisconnectConn(connID uuid.UUID, reason string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range h.users {
+		if c.ID == connID {
+			h.disconnectLocked(c, reason)
+			return true
+		}
+	}
+	return false
+}
+
+func (h *NotifyHub) disconnectLocked(c *ClientConn, reason string) {
+	c.closeCode = websocket.CloseNormalClosure
+	c.closeReason = reason
+	h.removeLocked(c)
+	h.unsubscribeAllLocked(c.UserID)
+}
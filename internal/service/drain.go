@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ErrDraining — реплика останавливается и не принимает новые подключения.
+var ErrDraining = errors.New("hub is draining")
+
+// defaultDrainTimeout — дедлайн Drain, если у ctx его нет.
+const defaultDrainTimeout = 10 * time.Second
+
+// Draining сообщает, что хаб останавливается (вызван Drain).
+func (h *NotifyHub) Draining() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.draining
+}
+
+// Drain останавливает хаб при остановке реплики: новые подключения отклоняются (ErrDraining),
+// очереди подключений дописываются в сокеты до дедлайна ctx, после чего клиенты получают
+// close-кадр 1012 (service restart) с подсказкой "reconnect_after_ms=N" — случайной задержкой
+// до reconnectHint, чтобы клиенты не переподключались одновременно.
+// Возвращает сообщения, которые не удалось отправить до дедлайна, по пользователям.
+func (h *NotifyHub) Drain(ctx context.Context, reconnectHint time.Duration) map[uuid.UUID][][]byte {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultDrainTimeout)
+	}
+
+	// Подключения удаляются из users и всех индексов под одной блокировкой: рассылки во время
+	// остановки (например, из Kafka) уже не попадают в закрытые очереди.
+	h.mu.Lock()
+	h.draining = true
+	conns := make([]*ClientConn, 0, len(h.users))
+	for _, c := range h.users {
+		var after time.Duration
+		if reconnectHint > 0 {
+			after = rand.N(reconnectHint)
+		}
+		c.closeCode = websocket.CloseServiceRestart
+		c.closeReason = fmt.Sprintf("service restart; reconnect_after_ms=%d", after.Milliseconds())
+		c.drainDeadline.Store(deadline.UnixNano())
+		h.removeLocked(c)
+		conns = append(conns, c)
+	}
+	// Отключены все пользователи, поэтому подписки на сессии снимаются целиком.
+	clear(h.sessions)
+	h.mu.Unlock()
+
+	// Запись в сокет ограничена дедлайном, поэтому WritePump завершается вскоре после него.
+	grace, cancel := context.WithDeadline(context.Background(), deadline.Add(time.Second))
+	defer cancel()
+	unsent := make(map[uuid.UUID][][]byte)
+	for _, c := range conns {
+		select {
+		case <-c.done:
+		case <-grace.Done():
+			slog.Warn("ws: drain: writer did not stop", "user_id", c.UserID, "conn_id", c.ID)
+			continue
+		}
+		var msgs [][]byte
+		for _, m := range c.unsent {
+			msgs = append(msgs, m.Data)
+		}
+		for m := range c.Send {
+			msgs = append(msgs, m.Data)
+		}
+		if len(msgs) > 0 {
+			unsent[c.UserID] = append(unsent[c.UserID], msgs...)
+		}
+	}
+	return unsent
+}
@@ -1,16 +1,32 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/notification-service/internal/metrics"
+	"github.com/psds-microservice/notification-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Типы адресатов рассылки (метка target в метриках).
+const (
+	TargetUser    = "user"
+	TargetSession = "session"
+	TargetRegion  = "region"
+	TargetRole    = "role"
 )
 
 // SessionBroadcaster — интерфейс для gRPC Deps (Dependency Inversion).
 type SessionBroadcaster interface {
-	BroadcastToSession(sessionID uuid.UUID, msg []byte)
+	BroadcastToSession(ctx context.Context, sessionID uuid.UUID, msg []byte)
 }
 
 type NotifyHub struct {
@@ -20,14 +36,44 @@ type NotifyHub struct {
 	regions       map[string]map[uuid.UUID]struct{}
 	roles         map[string]map[uuid.UUID]struct{}
 	sendQueueSize int
+	onAck         AckHandler
+	onHistory     HistoryHandler
+	onConnect     ConnectHandler
+	draining      bool
 }
 
+// AckHandler вызывается, когда клиент подтверждает получение уведомления.
+type AckHandler func(userID, notificationID uuid.UUID)
+
 type ClientConn struct {
-	UserID    uuid.UUID
-	Conn      *websocket.Conn
-	Send      chan []byte
-	Meta      ClientMetadata
-	sendOnce  sync.Once
+	// ID — идентификатор подключения (conn_id в логах): у пользователя может смениться подключение.
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Conn   *websocket.Conn
+	Send   chan Outbound
+	Meta   ClientMetadata
+	Codec  FrameCodec
+	// ConnectedAt — время регистрации подключения в хабе.
+	ConnectedAt time.Time
+	sendOnce    sync.Once
+
+	// done закрывается, когда WritePump завершился.
+	done chan struct{}
+	// Close-кадр, которым WritePump закрывает подключение, если его закрыл сервер
+	// (Drain, Disconnect); задаются до closeSend.
+	closeCode   int
+	closeReason string
+	// Состояние drain (см. NotifyHub.Drain): дедлайн записи и сообщения, которые WritePump не смог отправить.
+	drainDeadline atomic.Int64
+	unsent        []Outbound
+}
+
+// Outbound — сообщение в очереди подключения и контекст трассировки, в котором оно поставлено:
+// запись в сокет оформляется дочерним спаном.
+type Outbound struct {
+	Data   []byte
+	Trace  trace.SpanContext
+	Queued time.Time
 }
 
 // ClientMetadata описывает базовые атрибуты подключённого клиента
@@ -35,6 +81,16 @@ type ClientConn struct {
 type ClientMetadata struct {
 	Region string
 	Roles  []string
+	// Locale — язык клиента для шаблонов уведомлений (query locale или Accept-Language).
+	Locale string
+	// Subprotocol — согласованный при upgrade подпротокол (notify.v1.json / notify.v1.proto).
+	Subprotocol string
+	// BatchWindow > 0 включает coalescing: сообщения из Send, накопленные за окно,
+	// отправляются одним кадром (JSON-массив или Envelope type=batch), не более BatchMaxMessages за раз.
+	BatchWindow      time.Duration
+	BatchMaxMessages int
+	// RemoteAddr — адрес клиента (с учётом X-Forwarded-For).
+	RemoteAddr string
 }
 
 func NewNotifyHub(sendQueueSize int) *NotifyHub {
@@ -50,15 +106,37 @@ func NewNotifyHub(sendQueueSize int) *NotifyHub {
 	}
 }
 
+// SetAckHandler задаёт обработчик подтверждений (ack) от клиентов.
+func (h *NotifyHub) SetAckHandler(fn AckHandler) {
+	h.mu.Lock()
+	h.onAck = fn
+	h.mu.Unlock()
+}
+
+func (h *NotifyHub) ack(userID, notificationID uuid.UUID) {
+	h.mu.RLock()
+	fn := h.onAck
+	h.mu.RUnlock()
+	if fn != nil {
+		fn(userID, notificationID)
+	}
+}
+
 func (c *ClientConn) closeSend() { c.sendOnce.Do(func() { close(c.Send) }) }
 
-func (h *NotifyHub) Register(userID uuid.UUID, conn *websocket.Conn, meta ClientMetadata) *ClientConn {
+// Register регистрирует подключение пользователя (предыдущее подключение закрывается).
+// Во время остановки возвращает ErrDraining.
+func (h *NotifyHub) Register(userID uuid.UUID, conn *websocket.Conn, meta ClientMetadata) (*ClientConn, error) {
 	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		return nil, ErrDraining
+	}
 	if old, ok := h.users[userID]; ok {
 		old.closeSend()
 		delete(h.users, userID)
 	}
-	c := &ClientConn{UserID: userID, Conn: conn, Send: make(chan []byte, h.sendQueueSize), Meta: meta}
+	c := &ClientConn{ID: uuid.New(), UserID: userID, Conn: conn, Send: make(chan Outbound, h.sendQueueSize), Meta: meta, Codec: CodecFor(meta.Subprotocol), ConnectedAt: time.Now(), done: make(chan struct{})}
 	h.users[userID] = c
 	// Индексация по региону и ролям для agent routing.
 	if meta.Region != "" {
@@ -77,36 +155,59 @@ func (h *NotifyHub) Register(userID uuid.UUID, conn *websocket.Conn, meta Client
 		h.roles[role][userID] = struct{}{}
 	}
 	h.mu.Unlock()
-	return c
+	return c, nil
 }
 
 func (h *NotifyHub) Unregister(userID uuid.UUID) {
 	h.mu.Lock()
 	if c, ok := h.users[userID]; ok {
-		c.closeSend()
-		delete(h.users, userID)
-		// Удаляем пользователя из индексов регионов и ролей.
-		if c.Meta.Region != "" {
-			if m := h.regions[c.Meta.Region]; m != nil {
-				delete(m, userID)
-				if len(m) == 0 {
-					delete(h.regions, c.Meta.Region)
-				}
+		h.removeLocked(c)
+	}
+	h.unsubscribeAllLocked(userID)
+	h.mu.Unlock()
+}
+
+// UnregisterConn — Unregister для конкретного подключения: если пользователь уже переподключился
+// или подключение закрыто через Disconnect, новое подключение и его подписки не затрагиваются.
+func (h *NotifyHub) UnregisterConn(c *ClientConn) {
+	h.mu.Lock()
+	if h.users[c.UserID] == c {
+		h.removeLocked(c)
+		h.unsubscribeAllLocked(c.UserID)
+	}
+	h.mu.Unlock()
+}
+
+// removeLocked закрывает очередь подключения и удаляет его из users и индексов регионов и ролей.
+func (h *NotifyHub) removeLocked(c *ClientConn) {
+	c.closeSend()
+	delete(h.users, c.UserID)
+	if c.Meta.Region != "" {
+		if m := h.regions[c.Meta.Region]; m != nil {
+			delete(m, c.UserID)
+			if len(m) == 0 {
+				delete(h.regions, c.Meta.Region)
 			}
 		}
-		for _, role := range c.Meta.Roles {
-			if m := h.roles[role]; m != nil {
-				delete(m, userID)
-				if len(m) == 0 {
-					delete(h.roles, role)
-				}
+	}
+	for _, role := range c.Meta.Roles {
+		if m := h.roles[role]; m != nil {
+			delete(m, c.UserID)
+			if len(m) == 0 {
+				delete(h.roles, role)
 			}
 		}
 	}
-	for _, m := range h.sessions {
+}
+
+// unsubscribeAllLocked удаляет все подписки пользователя на сессии.
+func (h *NotifyHub) unsubscribeAllLocked(userID uuid.UUID) {
+	for sid, m := range h.sessions {
 		delete(m, userID)
+		if len(m) == 0 {
+			delete(h.sessions, sid)
+		}
 	}
-	h.mu.Unlock()
 }
 
 func (h *NotifyHub) SubscribeSession(sessionID, userID uuid.UUID) {
@@ -118,39 +219,106 @@ func (h *NotifyHub) SubscribeSession(sessionID, userID uuid.UUID) {
 	h.mu.Unlock()
 }
 
-func (h *NotifyHub) UnsubscribeSession(sessionID, userID uuid.UUID) {
+// UnsubscribeSession отписывает пользователя от сессии; false — пользователь не был подписан.
+func (h *NotifyHub) UnsubscribeSession(sessionID, userID uuid.UUID) bool {
 	h.mu.Lock()
-	if m := h.sessions[sessionID]; m != nil {
-		delete(m, userID)
+	defer h.mu.Unlock()
+	m := h.sessions[sessionID]
+	if _, ok := m[userID]; !ok {
+		return false
 	}
-	h.mu.Unlock()
+	delete(m, userID)
+	if len(m) == 0 {
+		delete(h.sessions, sessionID)
+	}
+	return true
 }
 
-func (h *NotifyHub) BroadcastToSession(sessionID uuid.UUID, msg []byte) {
+func (h *NotifyHub) BroadcastToSession(ctx context.Context, sessionID uuid.UUID, msg []byte) {
+	h.BroadcastTo(ctx, TargetSession, h.SessionSubscribers(sessionID), msg)
+}
+
+// SessionSubscribers возвращает пользователей, подписанных на сессию.
+func (h *NotifyHub) SessionSubscribers(sessionID uuid.UUID) []uuid.UUID {
 	h.mu.RLock()
-	m, ok := h.sessions[sessionID]
-	if !ok {
-		h.mu.RUnlock()
-		return
-	}
+	defer h.mu.RUnlock()
+	m := h.sessions[sessionID]
 	userIDs := make([]uuid.UUID, 0, len(m))
 	for uid := range m {
 		userIDs = append(userIDs, uid)
 	}
+	return userIDs
+}
+
+// SessionsOf возвращает сессии, на которые подписан пользователь.
+func (h *NotifyHub) SessionsOf(userID uuid.UUID) []uuid.UUID {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var out []uuid.UUID
+	for sid, m := range h.sessions {
+		if _, ok := m[userID]; ok {
+			out = append(out, sid)
+		}
+	}
+	return out
+}
+
+// LocaleOf возвращает язык, переданный клиентом при подключении ("" — офлайн или не передан).
+func (h *NotifyHub) LocaleOf(userID uuid.UUID) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if c := h.users[userID]; c != nil {
+		return c.Meta.Locale
+	}
+	return ""
+}
+
+// IsOnline сообщает, есть ли у пользователя активное WebSocket-подключение.
+func (h *NotifyHub) IsOnline(userID uuid.UUID) bool {
+	h.mu.RLock()
+	_, ok := h.users[userID]
 	h.mu.RUnlock()
-	h.BroadcastToUsers(userIDs, msg)
+	return ok
+}
+
+// SendToConn отправляет msg в очередь конкретного подключения, если оно ещё зарегистрировано;
+// false — подключение закрыто или его очередь полна.
+// Проверка под RLock защищает от отправки в канал, закрытый при повторном Register.
+func (h *NotifyHub) SendToConn(c *ClientConn, msg []byte) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.users[c.UserID] != c {
+		return false
+	}
+	select {
+	case c.Send <- Outbound{Data: msg, Queued: time.Now()}:
+		return true
+	default:
+		return false
+	}
 }
 
 // SendToUser sends msg to the user's channel. Holds RLock during the non-blocking send
 // so Unregister cannot close the channel between lookup and send (avoids send on closed channel panic).
 func (h *NotifyHub) SendToUser(userID uuid.UUID, msg []byte) {
+	h.SendTo(context.Background(), TargetUser, userID, msg)
+}
+
+// SendTo — SendToUser с типом адресата для метрик (TargetSession, TargetRegion, ...) и контекстом
+// трассировки, который продолжит спан записи в сокет.
+func (h *NotifyHub) SendTo(ctx context.Context, target string, userID uuid.UUID, msg []byte) {
+	out := Outbound{Data: msg, Trace: trace.SpanContextFromContext(ctx), Queued: time.Now()}
 	h.mu.RLock()
 	c := h.users[userID]
 	if c != nil {
+		metrics.WSSendQueueDepth.Observe(float64(len(c.Send)))
 		select {
-		case c.Send <- msg:
+		case c.Send <- out:
+			metrics.WSMessages.WithLabelValues(target, "sent").Inc()
 		default:
 			// queue full, drop
+			metrics.WSMessages.WithLabelValues(target, "dropped").Inc()
+			trace.SpanFromContext(ctx).AddEvent("ws.dropped", trace.WithAttributes(attribute.String("user_id", userID.String())))
 		}
 	}
 	h.mu.RUnlock()
@@ -158,8 +326,25 @@ func (h *NotifyHub) SendToUser(userID uuid.UUID, msg []byte) {
 
 // BroadcastToUsers отправляет сообщение конкретному набору пользователей.
 func (h *NotifyHub) BroadcastToUsers(userIDs []uuid.UUID, msg []byte) {
+	h.BroadcastTo(context.Background(), TargetUser, userIDs, msg)
+}
+
+// BroadcastTo отправляет сообщение пользователям; target — тип адресата для метрик.
+func (h *NotifyHub) BroadcastTo(ctx context.Context, target string, userIDs []uuid.UUID, msg []byte) {
 	for _, uid := range userIDs {
-		h.SendToUser(uid, msg)
+		h.SendTo(ctx, target, uid, msg)
+	}
+}
+
+// Stats возвращает число подключённых пользователей, сессий с подписчиками, регионов и ролей.
+func (h *NotifyHub) Stats() metrics.HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return metrics.HubStats{
+		Users:    len(h.users),
+		Sessions: len(h.sessions),
+		Regions:  len(h.regions),
+		Roles:    len(h.roles),
 	}
 }
 
@@ -179,20 +364,40 @@ func (h *NotifyHub) BroadcastToRegion(region string, msg []byte) {
 		userIDs = append(userIDs, uid)
 	}
 	h.mu.RUnlock()
-	h.BroadcastToUsers(userIDs, msg)
+	h.BroadcastTo(context.Background(), TargetRegion, userIDs, msg)
 }
 
 // BroadcastToRegions отправляет сообщение по нескольким регионам.
 func (h *NotifyHub) BroadcastToRegions(regions []string, msg []byte) {
+	h.BroadcastTo(context.Background(), TargetRegion, h.UsersInRegions(regions), msg)
+}
+
+// UsersInRegions возвращает пользователей, подключённых из любого из регионов (без повторов).
+func (h *NotifyHub) UsersInRegions(regions []string) []uuid.UUID {
+	return h.collectUsers(h.regions, regions)
+}
+
+// BroadcastToRoles отправляет сообщение всем пользователям с указанными ролями.
+func (h *NotifyHub) BroadcastToRoles(roles []string, msg []byte) {
+	h.BroadcastTo(context.Background(), TargetRole, h.UsersWithRoles(roles), msg)
+}
+
+// UsersWithRoles возвращает подключённых пользователей с любой из ролей (без повторов).
+func (h *NotifyHub) UsersWithRoles(roles []string) []uuid.UUID {
+	return h.collectUsers(h.roles, roles)
+}
+
+// collectUsers объединяет пользователей индекса по ключам.
+func (h *NotifyHub) collectUsers(index map[string]map[uuid.UUID]struct{}, keys []string) []uuid.UUID {
 	seen := make(map[uuid.UUID]struct{})
 	var targets []uuid.UUID
 	h.mu.RLock()
-	for _, region := range regions {
-		if region == "" {
+	defer h.mu.RUnlock()
+	for _, key := range keys {
+		if key == "" {
 			continue
 		}
-		m := h.regions[region]
-		for uid := range m {
+		for uid := range index[key] {
 			if _, ok := seen[uid]; ok {
 				continue
 			}
@@ -200,64 +405,187 @@ func (h *NotifyHub) BroadcastToRegions(regions []string, msg []byte) {
 			targets = append(targets, uid)
 		}
 	}
-	h.mu.RUnlock()
-	h.BroadcastToUsers(targets, msg)
+	return targets
 }
 
-// BroadcastToRoles отправляет сообщение всем пользователям с указанными ролями.
-func (h *NotifyHub) BroadcastToRoles(roles []string, msg []byte) {
-	seen := make(map[uuid.UUID]struct{})
-	var targets []uuid.UUID
-	h.mu.RLock()
-	for _, role := range roles {
-		if role == "" {
+func (c *ClientConn) WritePump() {
+	defer close(c.done)
+	defer c.Conn.Close()
+	var unsent []Outbound
+	if c.Meta.BatchWindow > 0 {
+		unsent = c.writeBatches()
+	} else {
+		unsent = c.writeSingles()
+	}
+	// Остановка реплики: если очередь не дописана, оставляем неотправленное для Drain.
+	deadline := c.drainDeadline.Load()
+	if deadline != 0 && unsent != nil {
+		c.unsent = unsent
+		return
+	}
+	if c.closeCode == 0 || unsent != nil {
+		return
+	}
+	// Подключение закрыл сервер: очередь дописана — прощаемся close-кадром.
+	if deadline == 0 {
+		deadline = time.Now().Add(closeWriteWait).UnixNano()
+	}
+	msg := websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+	_ = c.Conn.WriteControl(websocket.CloseMessage, msg, time.Unix(0, deadline))
+}
+
+// closeWriteWait — сколько ждать отправки close-кадра вне drain.
+const closeWriteWait = time.Second
+
+// writeSingles отправляет сообщения по одному; при ошибке записи возвращает неотправленное сообщение.
+func (c *ClientConn) writeSingles() []Outbound {
+	for msg := range c.Send {
+		frameType, frame, err := c.Codec.Encode(msg.Data)
+		if err != nil {
+			slog.Error("ws: encode frame", "user_id", c.UserID, "conn_id", c.ID, "error", err)
 			continue
 		}
-		m := h.roles[role]
-		for uid := range m {
-			if _, ok := seen[uid]; ok {
-				continue
+		if err := c.write(frameType, frame, []Outbound{msg}); err != nil {
+			return []Outbound{msg}
+		}
+	}
+	return nil
+}
+
+// write отправляет кадр в сокет. Если сообщения кадра поставлены в контексте трассировки,
+// запись оформляется спаном: дочерним для одного сообщения, со ссылками на все — для пачки.
+func (c *ClientConn) write(frameType int, frame []byte, msgs []Outbound) error {
+	if d := c.drainDeadline.Load(); d != 0 {
+		_ = c.Conn.SetWriteDeadline(time.Unix(0, d))
+	}
+	var parent trace.SpanContext
+	var links []trace.Link
+	for _, m := range msgs {
+		if m.Trace.IsValid() {
+			if !parent.IsValid() {
+				parent = m.Trace
 			}
-			seen[uid] = struct{}{}
-			targets = append(targets, uid)
+			links = append(links, trace.Link{SpanContext: m.Trace})
 		}
 	}
-	h.mu.RUnlock()
-	h.BroadcastToUsers(targets, msg)
+	if !parent.IsValid() {
+		return c.Conn.WriteMessage(frameType, frame)
+	}
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+	opts := []trace.SpanStartOption{trace.WithAttributes(
+		attribute.String("user_id", c.UserID.String()),
+		attribute.Int("ws.messages", len(msgs)),
+		attribute.Int64("ws.queue_wait_ms", time.Since(msgs[0].Queued).Milliseconds()),
+	)}
+	if len(msgs) > 1 {
+		ctx = context.Background()
+		opts = append(opts, trace.WithLinks(links...))
+	}
+	_, span := tracing.Tracer.Start(ctx, "ws write", opts...)
+	defer span.End()
+	err := c.Conn.WriteMessage(frameType, frame)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
 }
 
-func (c *ClientConn) WritePump() {
-	defer c.Conn.Close()
+// writeBatches — режим coalescing: первое сообщение открывает окно BatchWindow,
+// всё, что пришло в Send за это время (до BatchMaxMessages), уходит одним кадром.
+// При ошибке записи возвращает неотправленную пачку.
+func (c *ClientConn) writeBatches() []Outbound {
+	maxMessages := c.Meta.BatchMaxMessages
+	if maxMessages <= 0 {
+		maxMessages = DefaultBatchMaxMessages
+	}
+	timer := time.NewTimer(c.Meta.BatchWindow)
+	timer.Stop()
+	batch := make([]Outbound, 0, maxMessages)
+	data := make([][]byte, 0, maxMessages)
 	for msg := range c.Send {
-		if err := c.Conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-			return
+		batch = append(batch[:0], msg)
+		timer.Reset(c.Meta.BatchWindow)
+		open := true
+	collect:
+		for len(batch) < maxMessages {
+			select {
+			case m, ok := <-c.Send:
+				if !ok {
+					open = false
+					break collect
+				}
+				batch = append(batch, m)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		data = data[:0]
+		for _, m := range batch {
+			data = append(data, m.Data)
+		}
+		frameType, frame, err := c.Codec.EncodeBatch(data)
+		if err != nil {
+			slog.Error("ws: encode batch", "user_id", c.UserID, "conn_id", c.ID, "error", err)
+		} else if err := c.write(frameType, frame, batch); err != nil {
+			return batch
+		}
+		if !open {
+			return nil
 		}
 	}
+	return nil
 }
 
-type IncomingMessage struct {
-	SubscribeSession   string `json:"subscribe_session"`
-	UnsubscribeSession string `json:"unsubscribe_session"`
+// DefaultBatchMaxMessages — размер пачки в режиме coalescing, если он не задан для подключения.
+const DefaultBatchMaxMessages = 100
+
+// MaxClientMessageSize — максимальный размер входящего сообщения клиента.
+const MaxClientMessageSize = 64 * 1024
+
+func (c *ClientConn) reply(hub *NotifyHub, resp ServerResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	hub.SendToConn(c, data)
 }
 
 func (c *ClientConn) ReadPump(hub *NotifyHub, userID uuid.UUID) {
+	// ctx отменяется с закрытием подключения: history, выполняемые в фоне, не переживают клиента.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	defer c.Conn.Close()
+	c.Conn.SetReadLimit(MaxClientMessageSize)
+	historySlots := make(chan struct{}, maxHistoryInFlight)
 	for {
-		_, data, err := c.Conn.ReadMessage()
+		frameType, frame, err := c.Conn.ReadMessage()
 		if err != nil {
 			break
 		}
-		var msg IncomingMessage
-		_ = json.Unmarshal(data, &msg)
-		if msg.SubscribeSession != "" {
-			if sid, err := uuid.Parse(msg.SubscribeSession); err == nil {
-				hub.SubscribeSession(sid, userID)
-			}
+		data, err := c.Codec.Decode(frameType, frame)
+		if err != nil {
+			c.reply(hub, errorResponse("", ErrCodeInvalidFrame, "cannot decode frame: "+err.Error()))
+			continue
 		}
-		if msg.UnsubscribeSession != "" {
-			if sid, err := uuid.Parse(msg.UnsubscribeSession); err == nil {
-				hub.UnsubscribeSession(sid, userID)
-			}
+		req, errResp := ParseClientRequest(data)
+		if errResp != nil {
+			c.reply(hub, *errResp)
+			continue
+		}
+		if req.Type != RequestHistory {
+			c.reply(hub, hub.HandleClientRequest(ctx, userID, req))
+			continue
+		}
+		// history ходит в Postgres: выполняется в фоне, чтобы не задерживать ping, ack и subscribe.
+		select {
+		case historySlots <- struct{}{}:
+			go func() {
+				defer func() { <-historySlots }()
+				c.reply(hub, hub.HandleClientRequest(ctx, userID, req))
+			}()
+		default:
+			c.reply(hub, errorResponse(req.RequestID, ErrCodeUnavailable, "too many history requests in progress"))
 		}
 	}
 }
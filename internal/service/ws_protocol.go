@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы клиентских запросов WebSocket-протокола (см. api/ws_protocol.schema.json).
+const (
+	RequestSubscribe         = "subscribe"
+	RequestUnsubscribe       = "unsubscribe"
+	RequestAck               = "ack"
+	RequestPing              = "ping"
+	RequestListSubscriptions = "list_subscriptions"
+	RequestPresence          = "presence"
+	RequestHistory           = "history"
+)
+
+// Типы ответов сервера на клиентские запросы.
+const (
+	ResponseOK    = "ok"
+	ResponseError = "error"
+)
+
+// Сообщения, которые сервер отправляет без запроса клиента (в формате ServerResponse без request_id).
+const (
+	// PushUnreadCount — число непрочитанных уведомлений изменилось: data {"unread_count": N}.
+	PushUnreadCount = "unread_count"
+)
+
+// Коды ошибок в ответах типа "error".
+const (
+	ErrCodeInvalidJSON    = "invalid_json"
+	ErrCodeInvalidFrame   = "invalid_frame"
+	ErrCodeInvalidRequest = "invalid_request"
+	ErrCodeUnknownType    = "unknown_type"
+	ErrCodeInvalidID      = "invalid_id"
+	ErrCodeTooManyIDs     = "too_many_ids"
+	ErrCodeUnavailable    = "unavailable"
+)
+
+// MaxPresenceUserIDs — максимальное число user_ids в одном запросе presence.
+const MaxPresenceUserIDs = 100
+
+// MaxHistoryLimit — максимальное число уведомлений в одном ответе на history.
+const MaxHistoryLimit = 100
+
+// MaxHistoryEventTypes — максимальное число event_types в запросе history.
+const MaxHistoryEventTypes = 20
+
+// historyTimeout — сколько ждать HistoryHandler.
+const historyTimeout = 5 * time.Second
+
+// maxHistoryInFlight — сколько запросов history одного подключения выполняются одновременно.
+const maxHistoryInFlight = 4
+
+// MaxRequestIDLength — ограничение длины request_id, чтобы клиент не раздувал ответы.
+const MaxRequestIDLength = 128
+
+// ClientRequest — входящее сообщение клиента по WebSocket.
+type ClientRequest struct {
+	Type           string   `json:"type"`
+	RequestID      string   `json:"request_id,omitempty"`
+	SessionID      string   `json:"session_id,omitempty"`
+	NotificationID string   `json:"notification_id,omitempty"`
+	UserIDs        []string `json:"user_ids,omitempty"`
+
+	// Запрос history: необязательные фильтры по сессии (session_id), типам событий, времени
+	// (RFC 3339, полуинтервал [since, until)) и состоянию, размер страницы и page_token из предыдущего ответа.
+	EventTypes       []string `json:"event_types,omitempty"`
+	Since            string   `json:"since,omitempty"`
+	Until            string   `json:"until,omitempty"`
+	UnreadOnly       bool     `json:"unread_only,omitempty"`
+	ExcludeDismissed bool     `json:"exclude_dismissed,omitempty"`
+	Limit            int      `json:"limit,omitempty"`
+	PageToken        string   `json:"page_token,omitempty"`
+
+	// Устаревший формат без type: {"subscribe_session": "uuid"} / {"unsubscribe_session": "uuid"}.
+	SubscribeSession   string `json:"subscribe_session,omitempty"`
+	UnsubscribeSession string `json:"unsubscribe_session,omitempty"`
+
+	// alsoUnsubscribe — сессия, от которой отписывает устаревший кадр с обоими полями
+	// (сначала подписка, затем отписка — как раньше).
+	alsoUnsubscribe string
+}
+
+// ServerResponse — ответ сервера на ClientRequest.
+type ServerResponse struct {
+	Type      string         `json:"type"`
+	RequestID string         `json:"request_id,omitempty"`
+	Data      interface{}    `json:"data,omitempty"`
+	Error     *ProtocolError `json:"error,omitempty"`
+}
+
+// ProtocolError — описание ошибки для ответа типа "error".
+type ProtocolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func okResponse(requestID string, data interface{}) ServerResponse {
+	return ServerResponse{Type: ResponseOK, RequestID: requestID, Data: data}
+}
+
+func errorResponse(requestID, code, message string) ServerResponse {
+	return ServerResponse{Type: ResponseError, RequestID: requestID, Error: &ProtocolError{Code: code, Message: message}}
+}
+
+// ParseClientRequest разбирает и валидирует входящее сообщение.
+// Устаревшие сообщения без type приводятся к subscribe/unsubscribe.
+func ParseClientRequest(data []byte) (ClientRequest, *ServerResponse) {
+	var req ClientRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		resp := errorResponse("", ErrCodeInvalidJSON, "message is not a valid JSON object")
+		return req, &resp
+	}
+	if req.Type == "" {
+		switch {
+		case req.SubscribeSession != "":
+			req.Type, req.SessionID = RequestSubscribe, req.SubscribeSession
+			req.alsoUnsubscribe = req.UnsubscribeSession
+		case req.UnsubscribeSession != "":
+			req.Type, req.SessionID = RequestUnsubscribe, req.UnsubscribeSession
+		}
+	}
+	if resp := req.validate(); resp != nil {
+		return req, resp
+	}
+	return req, nil
+}
+
+func (r ClientRequest) validate() *ServerResponse {
+	fail := func(code, message string) *ServerResponse {
+		resp := errorResponse(r.RequestID, code, message)
+		return &resp
+	}
+	if len(r.RequestID) > MaxRequestIDLength {
+		r.RequestID = ""
+		return fail(ErrCodeInvalidRequest, "request_id is too long")
+	}
+	switch r.Type {
+	case "":
+		return fail(ErrCodeInvalidRequest, "type is required")
+	case RequestSubscribe, RequestUnsubscribe:
+		if r.SessionID == "" {
+			return fail(ErrCodeInvalidRequest, "session_id is required")
+		}
+		if _, err := uuid.Parse(strings.TrimSpace(r.SessionID)); err != nil {
+			return fail(ErrCodeInvalidID, "session_id must be a UUID")
+		}
+		if r.alsoUnsubscribe != "" {
+			if _, err := uuid.Parse(strings.TrimSpace(r.alsoUnsubscribe)); err != nil {
+				return fail(ErrCodeInvalidID, "unsubscribe_session must be a UUID")
+			}
+		}
+	case RequestAck:
+		if r.NotificationID == "" {
+			return fail(ErrCodeInvalidRequest, "notification_id is required")
+		}
+		if _, err := uuid.Parse(strings.TrimSpace(r.NotificationID)); err != nil {
+			return fail(ErrCodeInvalidID, "notification_id must be a UUID")
+		}
+	case RequestPresence:
+		if len(r.UserIDs) == 0 {
+			return fail(ErrCodeInvalidRequest, "user_ids is required")
+		}
+		if len(r.UserIDs) > MaxPresenceUserIDs {
+			return fail(ErrCodeTooManyIDs, "too many user_ids")
+		}
+		for _, id := range r.UserIDs {
+			if _, err := uuid.Parse(strings.TrimSpace(id)); err != nil {
+				return fail(ErrCodeInvalidID, "user_ids must contain UUIDs only")
+			}
+		}
+	case RequestHistory:
+		if r.SessionID != "" {
+			if _, err := uuid.Parse(strings.TrimSpace(r.SessionID)); err != nil {
+				return fail(ErrCodeInvalidID, "session_id must be a UUID")
+			}
+		}
+		if len(r.EventTypes) > MaxHistoryEventTypes {
+			return fail(ErrCodeInvalidRequest, "too many event_types")
+		}
+		for _, t := range []string{r.Since, r.Until} {
+			if _, err := parseHistoryTime(t); err != nil {
+				return fail(ErrCodeInvalidRequest, "since and until must be RFC 3339 timestamps")
+			}
+		}
+		if r.Limit < 0 || r.Limit > MaxHistoryLimit {
+			return fail(ErrCodeInvalidRequest, "limit must be between 0 and 100")
+		}
+	case RequestPing, RequestListSubscriptions:
+	default:
+		return fail(ErrCodeUnknownType, "unknown request type: "+r.Type)
+	}
+	return nil
+}
+
+// HandleClientRequest выполняет валидный запрос клиента и возвращает ответ;
+// ctx ограничивает запросы к хранилищу (history) временем жизни подключения.
+func (h *NotifyHub) HandleClientRequest(ctx context.Context, userID uuid.UUID, req ClientRequest) ServerResponse {
+	switch req.Type {
+	case RequestSubscribe:
+		sid := uuid.MustParse(strings.TrimSpace(req.SessionID))
+		h.SubscribeSession(sid, userID)
+		data := map[string]string{"session_id": sid.String()}
+		if req.alsoUnsubscribe != "" {
+			unsub := uuid.MustParse(strings.TrimSpace(req.alsoUnsubscribe))
+			h.UnsubscribeSession(unsub, userID)
+			data["unsubscribed_session_id"] = unsub.String()
+		}
+		return okResponse(req.RequestID, data)
+	case RequestUnsubscribe:
+		sid := uuid.MustParse(strings.TrimSpace(req.SessionID))
+		h.UnsubscribeSession(sid, userID)
+		return okResponse(req.RequestID, map[string]string{"session_id": sid.String()})
+	case RequestAck:
+		nid := uuid.MustParse(strings.TrimSpace(req.NotificationID))
+		h.ack(userID, nid)
+		return okResponse(req.RequestID, map[string]string{"notification_id": nid.String()})
+	case RequestPing:
+		return okResponse(req.RequestID, map[string]int64{"time": time.Now().UnixMilli()})
+	case RequestListSubscriptions:
+		sessions := h.SessionsOf(userID)
+		ids := make([]string, 0, len(sessions))
+		for _, sid := range sessions {
+			ids = append(ids, sid.String())
+		}
+		return okResponse(req.RequestID, map[string][]string{"session_ids": ids})
+	case RequestPresence:
+		online := make(map[string]bool, len(req.UserIDs))
+		for _, id := range req.UserIDs {
+			uid := uuid.MustParse(strings.TrimSpace(id))
+			online[uid.String()] = h.IsOnline(uid)
+		}
+		return okResponse(req.RequestID, map[string]map[string]bool{"online": online})
+	case RequestHistory:
+		return h.history(ctx, userID, req)
+	}
+	return errorResponse(req.RequestID, ErrCodeUnknownType, "unknown request type: "+req.Type)
+}
+
+// ErrInvalidRequest — HistoryHandler отклонил параметры запроса (ответ invalid_request с текстом ошибки).
+var ErrInvalidRequest = errors.New("invalid request")
+
+// HistoryQuery — параметры запроса history.
+type HistoryQuery struct {
+	// SessionID — uuid.Nil, если фильтра по сессии нет.
+	SessionID        uuid.UUID
+	EventTypes       []string
+	Since            time.Time
+	Until            time.Time
+	UnreadOnly       bool
+	ExcludeDismissed bool
+	Limit            int
+	PageToken        string
+}
+
+// HistoryHandler возвращает страницу истории уведомлений пользователя — data ответа на history.
+type HistoryHandler func(ctx context.Context, userID uuid.UUID, q HistoryQuery) (interface{}, error)
+
+// SetHistoryHandler задаёт обработчик запроса history; без него запрос отклоняется с кодом unavailable.
+func (h *NotifyHub) SetHistoryHandler(fn HistoryHandler) {
+	h.mu.Lock()
+	h.onHistory = fn
+	h.mu.Unlock()
+}
+
+func (h *NotifyHub) history(ctx context.Context, userID uuid.UUID, req ClientRequest) ServerResponse {
+	h.mu.RLock()
+	fn := h.onHistory
+	h.mu.RUnlock()
+	if fn == nil {
+		return errorResponse(req.RequestID, ErrCodeUnavailable, "history is not available")
+	}
+	q := HistoryQuery{
+		EventTypes:       req.EventTypes,
+		UnreadOnly:       req.UnreadOnly,
+		ExcludeDismissed: req.ExcludeDismissed,
+		Limit:            req.Limit,
+		PageToken:        req.PageToken,
+	}
+	if req.SessionID != "" {
+		q.SessionID = uuid.MustParse(strings.TrimSpace(req.SessionID))
+	}
+	q.Since, _ = parseHistoryTime(req.Since)
+	q.Until, _ = parseHistoryTime(req.Until)
+
+	ctx, cancel := context.WithTimeout(ctx, historyTimeout)
+	defer cancel()
+	data, err := fn(ctx, userID, q)
+	if errors.Is(err, ErrInvalidRequest) {
+		return errorResponse(req.RequestID, ErrCodeInvalidRequest, err.Error())
+	}
+	if err != nil {
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			return errorResponse(req.RequestID, ErrCodeUnavailable, "connection closed")
+		}
+		slog.Error("ws: history", "user_id", userID, "error", err)
+		return errorResponse(req.RequestID, ErrCodeUnavailable, "history is temporarily unavailable")
+	}
+	return okResponse(req.RequestID, data)
+}
+
+// parseHistoryTime разбирает время RFC 3339; пустая строка — нулевое время.
+func parseHistoryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// SendUnreadCount отправляет пользователю текущее число непрочитанных уведомлений.
+func (h *NotifyHub) SendUnreadCount(ctx context.Context, userID uuid.UUID, count int) {
+	data, err := json.Marshal(ServerResponse{Type: PushUnreadCount, Data: map[string]int{"unread_count": count}})
+	if err != nil {
+		return
+	}
+	h.SendTo(ctx, TargetUser, userID, data)
+}
+
+// ConnectHandler вызывается, когда пользователь подключился (например, чтобы отправить ему начальное состояние).
+type ConnectHandler func(ctx context.Context, userID uuid.UUID)
+
+// SetConnectHandler задаёт обработчик подключений.
+func (h *NotifyHub) SetConnectHandler(fn ConnectHandler) {
+	h.mu.Lock()
+	h.onConnect = fn
+	h.mu.Unlock()
+}
+
+// NotifyConnected вызывает обработчик подключений; WebSocket-обработчик вызывает его после Register.
+func (h *NotifyHub) NotifyConnected(ctx context.Context, userID uuid.UUID) {
+	h.mu.RLock()
+	fn := h.onConnect
+	h.mu.RUnlock()
+	if fn != nil {
+		fn(ctx, userID)
+	}
+}
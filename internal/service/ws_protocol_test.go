@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseClientRequest(t *testing.T) {
+	const sid = "6f1c2b7e-8a3d-4c5e-9f01-23456789abcd"
+	tests := []struct {
+		name      string
+		data      string
+		wantType  string
+		wantCode  string
+		requestID string
+	}{
+		{"not json", `subscribe`, "", ErrCodeInvalidJSON, ""},
+		{"no type", `{"request_id":"1"}`, "", ErrCodeInvalidRequest, "1"},
+		{"unknown type", `{"type":"publish","request_id":"1"}`, "publish", ErrCodeUnknownType, "1"},
+		{"request_id too long", `{"type":"ping","request_id":"` + strings.Repeat("x", MaxRequestIDLength+1) + `"}`, RequestPing, ErrCodeInvalidRequest, ""},
+		{"ping", `{"type":"ping","request_id":"1"}`, RequestPing, "", ""},
+		{"list subscriptions", `{"type":"list_subscriptions"}`, RequestListSubscriptions, "", ""},
+		{"subscribe", `{"type":"subscribe","session_id":"` + sid + `"}`, RequestSubscribe, "", ""},
+		{"subscribe without session", `{"type":"subscribe","request_id":"2"}`, RequestSubscribe, ErrCodeInvalidRequest, "2"},
+		{"subscribe bad session", `{"type":"subscribe","session_id":"abc"}`, RequestSubscribe, ErrCodeInvalidID, ""},
+		{"legacy subscribe", `{"subscribe_session":"` + sid + `"}`, RequestSubscribe, "", ""},
+		{"legacy subscribe and unsubscribe", `{"subscribe_session":"` + sid + `","unsubscribe_session":"` + sid + `"}`, RequestSubscribe, "", ""},
+		{"legacy bad unsubscribe", `{"subscribe_session":"` + sid + `","unsubscribe_session":"x"}`, RequestSubscribe, ErrCodeInvalidID, ""},
+		{"legacy unsubscribe", `{"unsubscribe_session":"` + sid + `"}`, RequestUnsubscribe, "", ""},
+		{"ack", `{"type":"ack","notification_id":"` + sid + `"}`, RequestAck, "", ""},
+		{"ack without id", `{"type":"ack"}`, RequestAck, ErrCodeInvalidRequest, ""},
+		{"ack bad id", `{"type":"ack","notification_id":"1"}`, RequestAck, ErrCodeInvalidID, ""},
+		{"presence", `{"type":"presence","user_ids":["` + sid + `"]}`, RequestPresence, "", ""},
+		{"presence without ids", `{"type":"presence","user_ids":[]}`, RequestPresence, ErrCodeInvalidRequest, ""},
+		{"presence bad id", `{"type":"presence","user_ids":["` + sid + `","x"]}`, RequestPresence, ErrCodeInvalidID, ""},
+		{"presence too many ids", `{"type":"presence","user_ids":[` + strings.Repeat(`"`+sid+`",`, MaxPresenceUserIDs) + `"` + sid + `"]}`, RequestPresence, ErrCodeTooManyIDs, ""},
+		{"history", `{"type":"history"}`, RequestHistory, "", ""},
+		{"history with filters", `{"type":"history","session_id":"` + sid + `","event_types":["a"],"since":"2026-10-18T09:00:00Z","until":"2026-10-18T10:00:00+03:00","limit":100}`, RequestHistory, "", ""},
+		{"history bad session", `{"type":"history","session_id":"x"}`, RequestHistory, ErrCodeInvalidID, ""},
+		{"history bad since", `{"type":"history","since":"yesterday"}`, RequestHistory, ErrCodeInvalidRequest, ""},
+		{"history negative limit", `{"type":"history","limit":-1}`, RequestHistory, ErrCodeInvalidRequest, ""},
+		{"history limit too large", `{"type":"history","limit":101}`, RequestHistory, ErrCodeInvalidRequest, ""},
+		{"history too many event types", `{"type":"history","event_types":[` + strings.Repeat(`"a",`, MaxHistoryEventTypes) + `"a"]}`, RequestHistory, ErrCodeInvalidRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, resp := ParseClientRequest([]byte(tt.data))
+			if req.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", req.Type, tt.wantType)
+			}
+			if tt.wantCode == "" {
+				if resp != nil {
+					t.Fatalf("unexpected error response: %+v", resp.Error)
+				}
+				return
+			}
+			if resp == nil || resp.Type != ResponseError || resp.Error == nil {
+				t.Fatalf("response = %+v, want error %s", resp, tt.wantCode)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("code = %s (%s), want %s", resp.Error.Code, resp.Error.Message, tt.wantCode)
+			}
+			if resp.RequestID != tt.requestID {
+				t.Errorf("request_id = %q, want %q", resp.RequestID, tt.requestID)
+			}
+		})
+	}
+}
+
+func TestHistoryCanceledWithConnection(t *testing.T) {
+	hub := NewNotifyHub(16)
+	started := make(chan struct{})
+	hub.SetHistoryHandler(func(ctx context.Context, _ uuid.UUID, _ HistoryQuery) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan ServerResponse)
+	go func() {
+		done <- hub.HandleClientRequest(ctx, uuid.New(), ClientRequest{Type: RequestHistory, RequestID: "h"})
+	}()
+	<-started
+	cancel()
+	select {
+	case resp := <-done:
+		if resp.Type != ResponseError || resp.Error.Code != ErrCodeUnavailable || resp.RequestID != "h" {
+			t.Errorf("response = %+v, want unavailable error", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("history was not canceled with the connection context")
+	}
+}
+
+func TestLegacySubscribeAndUnsubscribe(t *testing.T) {
+	hub := NewNotifyHub(16)
+	userID := uuid.New()
+	keep, leave := uuid.New(), uuid.New()
+	hub.SubscribeSession(leave, userID)
+
+	req, errResp := ParseClientRequest([]byte(`{"subscribe_session":"` + keep.String() + `","unsubscribe_session":"` + leave.String() + `"}`))
+	if errResp != nil {
+		t.Fatalf("ParseClientRequest() error = %+v", errResp.Error)
+	}
+	if resp := hub.HandleClientRequest(context.Background(), userID, req); resp.Type != ResponseOK {
+		t.Fatalf("response = %+v, want ok", resp)
+	}
+	sessions := hub.SessionsOf(userID)
+	if len(sessions) != 1 || sessions[0] != keep {
+		t.Errorf("sessions = %v, want [%s]", sessions, keep)
+	}
+}
@@ -0,0 +1,316 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/notification-service/internal/repository"
+)
+
+// Data — данные, доступные в шаблоне.
+type Data struct {
+	Event     string
+	Locale    string
+	UserID    string
+	SessionID string
+	Payload   map[string]interface{}
+	CreatedAt time.Time
+}
+
+// DataFromEvent собирает данные шаблона из записи notification_events.
+func DataFromEvent(ev repository.Event) Data {
+	data := Data{Event: ev.EventType, UserID: ev.UserID.String(), CreatedAt: ev.CreatedAt}
+	if ev.SessionID.Valid {
+		data.SessionID = ev.SessionID.UUID.String()
+	}
+	if len(ev.Payload) > 0 {
+		_ = json.Unmarshal(ev.Payload, &data.Payload)
+	}
+	return data
+}
+
+// Rendered — отрендеренные заголовок и текст уведомления и язык шаблона, которым они получены.
+type Rendered struct {
+	Locale string
+	Title  string
+	Body   string
+}
+
+// LocaleResolver возвращает языки пользователей (notification_contacts.locale).
+type LocaleResolver interface {
+	Locales(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+type key struct{ event, locale string }
+
+type compiled struct {
+	title, body *template.Template
+}
+
+// Catalog — шаблоны уведомлений по типу события и языку: из каталога файлов
+// (<event_type>.<locale>.tmpl с блоками {{define "title"}} и {{define "body"}}) и из Postgres;
+// шаблон из Postgres важнее файла с тем же ключом.
+type Catalog struct {
+	store         *repository.TemplateRepository
+	locales       LocaleResolver
+	defaultLocale string
+	files         map[key]*compiled
+
+	mu     sync.RWMutex
+	stored map[key]*compiled
+	events map[string]bool
+}
+
+// NewCatalog загружает шаблоны из dir (если задан); шаблоны из store подгружает Reload.
+func NewCatalog(store *repository.TemplateRepository, locales LocaleResolver, dir, defaultLocale string) (*Catalog, error) {
+	c := &Catalog{
+		store:         store,
+		locales:       locales,
+		defaultLocale: NormalizeLocale(defaultLocale),
+		files:         make(map[key]*compiled),
+		stored:        make(map[key]*compiled),
+	}
+	if dir != "" {
+		if err := c.loadDir(os.DirFS(dir)); err != nil {
+			return nil, err
+		}
+	}
+	c.events = c.eventSet(c.stored)
+	return c, nil
+}
+
+func (c *Catalog) loadDir(fsys fs.FS) error {
+	matches, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		name := strings.TrimSuffix(path, ".tmpl")
+		i := strings.LastIndex(name, ".")
+		if i <= 0 {
+			return fmt.Errorf("templates: %s: expected <event_type>.<locale>.tmpl", path)
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("templates: read %s: %w", path, err)
+		}
+		t, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("templates: parse %s: %w", path, err)
+		}
+		if t.Lookup("title") == nil || t.Lookup("body") == nil {
+			return fmt.Errorf("templates: %s must define \"title\" and \"body\"", path)
+		}
+		c.files[key{name[:i], NormalizeLocale(name[i+1:])}] = &compiled{title: t.Lookup("title"), body: t.Lookup("body")}
+	}
+	return nil
+}
+
+// Compile проверяет шаблоны заголовка и текста.
+func Compile(title, body string) error {
+	_, err := compile(title, body)
+	return err
+}
+
+func compile(title, body string) (*compiled, error) {
+	t, err := template.New("title").Funcs(funcs).Option("missingkey=zero").Parse(title)
+	if err != nil {
+		return nil, fmt.Errorf("title: %w", err)
+	}
+	b, err := template.New("body").Funcs(funcs).Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return &compiled{title: t, body: b}, nil
+}
+
+// Reload перечитывает шаблоны из Postgres.
+func (c *Catalog) Reload(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	list, err := c.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	stored := make(map[key]*compiled, len(list))
+	for _, t := range list {
+		ct, err := compile(t.Title, t.Body)
+		if err != nil {
+			slog.ErrorContext(ctx, "templates: compile", "event", t.EventType, "locale", t.Locale, "error", err)
+			continue
+		}
+		stored[key{t.EventType, NormalizeLocale(t.Locale)}] = ct
+	}
+	events := c.eventSet(stored)
+	c.mu.Lock()
+	c.stored, c.events = stored, events
+	c.mu.Unlock()
+	return nil
+}
+
+// eventSet — события, для которых есть шаблон в файлах или в stored.
+func (c *Catalog) eventSet(stored map[key]*compiled) map[string]bool {
+	events := make(map[string]bool)
+	for k := range c.files {
+		events[k.event] = true
+	}
+	for k := range stored {
+		events[k.event] = true
+	}
+	return events
+}
+
+// Run перечитывает шаблоны из Postgres раз в interval, чтобы изменения через API
+// на одной реплике доходили до остальных.
+func (c *Catalog) Run(ctx context.Context, interval time.Duration) {
+	if c.store == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reload(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "templates: reload", "error", err)
+			}
+		}
+	}
+}
+
+// Has сообщает, есть ли для события шаблон хотя бы на одном языке.
+func (c *Catalog) Has(event string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.events[event]
+}
+
+func (c *Catalog) lookup(event, locale string) *compiled {
+	k := key{event, locale}
+	c.mu.RLock()
+	t := c.stored[k]
+	c.mu.RUnlock()
+	if t != nil {
+		return t
+	}
+	return c.files[k]
+}
+
+// Render рендерит шаблон события на языке locale, проходя цепочку: pt-br → pt → язык по умолчанию.
+// false — шаблона нет ни на одном языке цепочки или рендеринг не удался.
+func (c *Catalog) Render(event, locale string, data Data) (Rendered, bool) {
+	for _, l := range FallbackChain(locale, c.defaultLocale) {
+		t := c.lookup(event, l)
+		if t == nil {
+			continue
+		}
+		data.Locale = l
+		r, err := execute(t, data)
+		if err != nil {
+			slog.Error("templates: render", "event", event, "locale", l, "error", err)
+			return Rendered{}, false
+		}
+		r.Locale = l
+		return r, true
+	}
+	return Rendered{}, false
+}
+
+// Locales возвращает языки пользователей из их настроек.
+func (c *Catalog) Locales(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	if c.locales == nil {
+		return nil, nil
+	}
+	return c.locales.Locales(ctx, userIDs)
+}
+
+// RenderEvent рендерит событие из очереди доставок на языке получателя (email, push).
+func (c *Catalog) RenderEvent(ctx context.Context, ev repository.Event) (Rendered, bool) {
+	if !c.Has(ev.EventType) {
+		return Rendered{}, false
+	}
+	locales, err := c.Locales(ctx, []uuid.UUID{ev.UserID})
+	if err != nil {
+		slog.ErrorContext(ctx, "templates: locale", "user_id", ev.UserID, "error", err)
+	}
+	return c.Render(ev.EventType, locales[ev.UserID], DataFromEvent(ev))
+}
+
+func execute(t *compiled, data Data) (Rendered, error) {
+	var title, body limitedWriter
+	if err := t.title.Execute(&title, data); err != nil {
+		return Rendered{}, err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Rendered{}, err
+	}
+	return Rendered{Title: strings.TrimSpace(title.b.String()), Body: strings.TrimSpace(body.b.String())}, nil
+}
+
+// NormalizeLocale приводит язык к виду ключа шаблона: "pt_BR" → "pt-br".
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// FallbackChain возвращает языки в порядке поиска шаблона: сам язык, его базовые
+// подтеги ("zh-hant-tw" → "zh-hant" → "zh"), затем язык по умолчанию и его базовые подтеги.
+func FallbackChain(locale, defaultLocale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(l string) {
+		for l = NormalizeLocale(l); l != ""; {
+			if !seen[l] {
+				seen[l] = true
+				chain = append(chain, l)
+			}
+			i := strings.LastIndex(l, "-")
+			if i < 0 {
+				break
+			}
+			l = l[:i]
+		}
+	}
+	add(locale)
+	add(defaultLocale)
+	return chain
+}
+
+// List возвращает шаблоны из Postgres.
+func (c *Catalog) List(ctx context.Context) ([]repository.Template, error) {
+	return c.store.List(ctx)
+}
+
+// Set сохраняет шаблон в Postgres и сразу обновляет каталог этой реплики.
+func (c *Catalog) Set(ctx context.Context, t repository.Template) (repository.Template, error) {
+	saved, err := c.store.Set(ctx, t)
+	if err != nil {
+		return saved, err
+	}
+	if err := c.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "templates: reload", "error", err)
+	}
+	return saved, nil
+}
+
+// Delete удаляет шаблон из Postgres и сразу обновляет каталог этой реплики.
+func (c *Catalog) Delete(ctx context.Context, eventType, locale string) error {
+	if err := c.store.Delete(ctx, eventType, locale); err != nil {
+		return err
+	}
+	if err := c.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "templates: reload", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testCatalog(t *testing.T, files map[string]string) *Catalog {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for name, text := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(text)}
+	}
+	c := &Catalog{defaultLocale: "en", files: make(map[key]*compiled), stored: make(map[key]*compiled)}
+	if err := c.loadDir(fsys); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCatalogRender(t *testing.T) {
+	c := testCatalog(t, map[string]string{
+		"session.created.en.tmpl": `{{define "title"}}New session{{end}}{{define "body"}}{{.Payload.client}} is waiting{{end}}`,
+		"session.created.ru.tmpl": `{{define "title"}}Новая сессия{{end}}{{define "body"}}{{.Payload.client}} ждёт ответа{{end}}`,
+		"queue.size.ru.tmpl":      `{{define "title"}}Очередь{{end}}{{define "body"}}{{.Payload.n}} {{plural .Payload.n "клиент" "клиента" "клиентов"}}{{end}}`,
+	})
+	data := func(payload map[string]interface{}) Data { return Data{Payload: payload} }
+	tests := []struct {
+		name   string
+		event  string
+		locale string
+		data   Data
+		want   Rendered
+		ok     bool
+	}{
+		{"exact locale", "session.created", "ru", data(map[string]interface{}{"client": "Анна"}), Rendered{"ru", "Новая сессия", "Анна ждёт ответа"}, true},
+		{"base subtag", "session.created", "ru-RU", data(map[string]interface{}{"client": "Анна"}), Rendered{"ru", "Новая сессия", "Анна ждёт ответа"}, true},
+		{"default locale", "session.created", "de", data(map[string]interface{}{"client": "Anna"}), Rendered{"en", "New session", "Anna is waiting"}, true},
+		{"empty locale", "session.created", "", data(map[string]interface{}{"client": "Anna"}), Rendered{"en", "New session", "Anna is waiting"}, true},
+		{"missing key", "session.created", "en", data(nil), Rendered{"en", "New session", "<no value> is waiting"}, true},
+		{"plural", "queue.size", "ru", data(map[string]interface{}{"n": 22.0}), Rendered{"ru", "Очередь", "22 клиента"}, true},
+		{"no default locale template", "queue.size", "de", data(nil), Rendered{}, false},
+		{"unknown event", "session.closed", "en", data(nil), Rendered{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Render(tt.event, tt.locale, tt.data)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Render() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExecuteOutputLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		size    int
+		wantErr error
+	}{
+		{"under limit", `{{.Payload.text}}`, maxOutput - 1, nil},
+		{"at limit", `{{.Payload.text}}`, maxOutput, nil},
+		{"over limit", `{{.Payload.text}}`, maxOutput + 1, errOutputTooLarge},
+		{"over limit in range", `{{range .Payload.items}}{{.}}{{end}}`, 0, errOutputTooLarge},
+	}
+	items := make([]interface{}, maxOutput/8+1)
+	for i := range items {
+		items[i] = "12345678"
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := compile("title", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := execute(tpl, Data{Payload: map[string]interface{}{"text": strings.Repeat("x", tt.size), "items": items}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(r.Body) != tt.size {
+				t.Errorf("len(Body) = %d, want %d", len(r.Body), tt.size)
+			}
+		})
+	}
+}
+
+func TestLoadDirRequiresBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		text string
+	}{
+		{"no locale", "session.tmpl", `{{define "title"}}t{{end}}{{define "body"}}b{{end}}`},
+		{"no body", "session.created.en.tmpl", `{{define "title"}}t{{end}}`},
+		{"syntax error", "session.created.en.tmpl", `{{define "title"}}{{.Payload{{end}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Catalog{files: make(map[key]*compiled)}
+			if err := c.loadDir(fstest.MapFS{tt.file: {Data: []byte(tt.text)}}); err == nil {
+				t.Error("loadDir() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		locale, def string
+		want        []string
+	}{
+		{"pt_BR", "en", []string{"pt-br", "pt", "en"}},
+		{"zh-Hant-TW", "en-US", []string{"zh-hant-tw", "zh-hant", "zh", "en-us", "en"}},
+		{"en", "en", []string{"en"}},
+		{"", "ru", []string{"ru"}},
+	}
+	for _, tt := range tests {
+		if got := FallbackChain(tt.locale, tt.def); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("FallbackChain(%q, %q) = %v, want %v", tt.locale, tt.def, got, tt.want)
+		}
+	}
+}
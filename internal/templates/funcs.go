@@ -0,0 +1,136 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxOutput — предел размера отрендеренного заголовка или текста.
+const maxOutput = 16 << 10
+
+var errOutputTooLarge = errors.New("template output too large")
+
+// funcs — безопасный набор функций шаблонов: только преобразования данных, без доступа к окружению.
+var funcs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"truncate":   truncate,
+	"default":    defaultValue,
+	"join":       join,
+	"formatTime": formatTime,
+	"plural":     plural,
+}
+
+// truncate обрезает строку до n символов, добавляя «…».
+func truncate(n int, s string) string {
+	r := []rune(s)
+	if n < 0 || len(r) <= n {
+		return s
+	}
+	if n == 0 {
+		return ""
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// defaultValue возвращает def, если v пустое (nil, "", 0, пустой список).
+func defaultValue(def, v interface{}) interface{} {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+	return v
+}
+
+// join склеивает элементы списка через sep.
+func join(sep string, v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// formatTime форматирует time.Time или строку RFC 3339 по layout Go.
+func formatTime(layout string, v interface{}) string {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout)
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed.Format(layout)
+		}
+		return t
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// plural выбирает форму слова по числу. С двумя формами — правило английского
+// ({{plural .Payload.count "message" "messages"}}), с тремя — русского: one (1, 21),
+// few (2–4, 22–24), many (остальное).
+func plural(n interface{}, forms ...string) (string, error) {
+	if len(forms) != 2 && len(forms) != 3 {
+		return "", errors.New("plural: expected 2 or 3 forms")
+	}
+	var v int64
+	switch x := n.(type) {
+	case int:
+		v = int64(x)
+	case int64:
+		v = x
+	case float64:
+		v = int64(math.Abs(x))
+	default:
+		return forms[len(forms)-1], nil
+	}
+	if v < 0 {
+		v = -v
+	}
+	if len(forms) == 2 {
+		if v == 1 {
+			return forms[0], nil
+		}
+		return forms[1], nil
+	}
+	switch {
+	case v%10 == 1 && v%100 != 11:
+		return forms[0], nil
+	case v%10 >= 2 && v%10 <= 4 && (v%100 < 12 || v%100 > 14):
+		return forms[1], nil
+	default:
+		return forms[2], nil
+	}
+}
+
+// limitedWriter прерывает рендеринг, если результат превышает maxOutput.
+type limitedWriter struct {
+	b strings.Builder
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.b.Len()+len(p) > maxOutput {
+		return 0, errOutputTooLarge
+	}
+	return w.b.Write(p)
+}
@@ -1,7 +1,13 @@
 package constants
 
+// ServiceName — имя сервиса в трассировке.
+const ServiceName = "notification-service"
+
 const (
 	PathHealth  = "/health"
 	PathReady   = "/ready"
 	PathSwagger = "/swagger"
+	PathMetrics = "/metrics"
+
+	PathAdminLogLevel = "/admin/log-level"
 )
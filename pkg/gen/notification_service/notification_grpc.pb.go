@@ -19,7 +19,37 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	NotificationService_NotifySession_FullMethodName = "/notification_service.NotificationService/NotifySession"
+	NotificationService_NotifySession_FullMethodName               = "/notification_service.NotificationService/NotifySession"
+	NotificationService_SendNotification_FullMethodName            = "/notification_service.NotificationService/SendNotification"
+	NotificationService_SetUserContact_FullMethodName              = "/notification_service.NotificationService/SetUserContact"
+	NotificationService_SetUserLocale_FullMethodName               = "/notification_service.NotificationService/SetUserLocale"
+	NotificationService_ScheduleNotification_FullMethodName        = "/notification_service.NotificationService/ScheduleNotification"
+	NotificationService_GetScheduledNotification_FullMethodName    = "/notification_service.NotificationService/GetScheduledNotification"
+	NotificationService_CancelScheduledNotification_FullMethodName = "/notification_service.NotificationService/CancelScheduledNotification"
+	NotificationService_RegisterDevice_FullMethodName              = "/notification_service.NotificationService/RegisterDevice"
+	NotificationService_UnregisterDevice_FullMethodName            = "/notification_service.NotificationService/UnregisterDevice"
+	NotificationService_ListPreferences_FullMethodName             = "/notification_service.NotificationService/ListPreferences"
+	NotificationService_GetPreference_FullMethodName               = "/notification_service.NotificationService/GetPreference"
+	NotificationService_SetPreference_FullMethodName               = "/notification_service.NotificationService/SetPreference"
+	NotificationService_DeletePreference_FullMethodName            = "/notification_service.NotificationService/DeletePreference"
+	NotificationService_GetQuietHours_FullMethodName               = "/notification_service.NotificationService/GetQuietHours"
+	NotificationService_SetQuietHours_FullMethodName               = "/notification_service.NotificationService/SetQuietHours"
+	NotificationService_DeleteQuietHours_FullMethodName            = "/notification_service.NotificationService/DeleteQuietHours"
+	NotificationService_GetDigest_FullMethodName                   = "/notification_service.NotificationService/GetDigest"
+	NotificationService_SetDigest_FullMethodName                   = "/notification_service.NotificationService/SetDigest"
+	NotificationService_DeleteDigest_FullMethodName                = "/notification_service.NotificationService/DeleteDigest"
+	NotificationService_ListTemplates_FullMethodName               = "/notification_service.NotificationService/ListTemplates"
+	NotificationService_SetTemplate_FullMethodName                 = "/notification_service.NotificationService/SetTemplate"
+	NotificationService_DeleteTemplate_FullMethodName              = "/notification_service.NotificationService/DeleteTemplate"
+	NotificationService_CreateWebhook_FullMethodName               = "/notification_service.NotificationService/CreateWebhook"
+	NotificationService_ListWebhooks_FullMethodName                = "/notification_service.NotificationService/ListWebhooks"
+	NotificationService_GetWebhook_FullMethodName                  = "/notification_service.NotificationService/GetWebhook"
+	NotificationService_UpdateWebhook_FullMethodName               = "/notification_service.NotificationService/UpdateWebhook"
+	NotificationService_DeleteWebhook_FullMethodName               = "/notification_service.NotificationService/DeleteWebhook"
+	NotificationService_ListWebhookDeliveries_FullMethodName       = "/notification_service.NotificationService/ListWebhookDeliveries"
+	NotificationService_ListNotifications_FullMethodName           = "/notification_service.NotificationService/ListNotifications"
+	NotificationService_MarkNotifications_FullMethodName           = "/notification_service.NotificationService/MarkNotifications"
+	NotificationService_GetUnreadCount_FullMethodName              = "/notification_service.NotificationService/GetUnreadCount"
 )
 
 // NotificationServiceClient is the client API for NotificationService service.
@@ -27,6 +57,46 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NotificationServiceClient interface {
 	NotifySession(ctx context.Context, in *NotifySessionRequest, opts ...grpc.CallOption) (*NotifySessionResponse, error)
+	// Отправка события получателям (сессия, пользователи, регионы, роли) через общий конвейер маршрутизации, как из Kafka.
+	SendNotification(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*SendNotificationResponse, error)
+	SetUserContact(ctx context.Context, in *SetUserContactRequest, opts ...grpc.CallOption) (*SetUserContactResponse, error)
+	SetUserLocale(ctx context.Context, in *SetUserLocaleRequest, opts ...grpc.CallOption) (*SetUserLocaleResponse, error)
+	// Отложенные уведомления: отправляются в deliver_at (или через delay) тем же конвейером, что события из Kafka.
+	ScheduleNotification(ctx context.Context, in *ScheduleNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error)
+	GetScheduledNotification(ctx context.Context, in *GetScheduledNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error)
+	CancelScheduledNotification(ctx context.Context, in *CancelScheduledNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error)
+	// Push-токены устройств пользователя.
+	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceResponse, error)
+	UnregisterDevice(ctx context.Context, in *UnregisterDeviceRequest, opts ...grpc.CallOption) (*UnregisterDeviceResponse, error)
+	// Настройки уведомлений пользователя по типам событий ("*" — по умолчанию).
+	ListPreferences(ctx context.Context, in *ListPreferencesRequest, opts ...grpc.CallOption) (*ListPreferencesResponse, error)
+	GetPreference(ctx context.Context, in *GetPreferenceRequest, opts ...grpc.CallOption) (*Preference, error)
+	SetPreference(ctx context.Context, in *SetPreferenceRequest, opts ...grpc.CallOption) (*Preference, error)
+	DeletePreference(ctx context.Context, in *DeletePreferenceRequest, opts ...grpc.CallOption) (*DeletePreferenceResponse, error)
+	// Окно тишины: некритичные push/email откладываются до его окончания.
+	GetQuietHours(ctx context.Context, in *GetQuietHoursRequest, opts ...grpc.CallOption) (*QuietHours, error)
+	SetQuietHours(ctx context.Context, in *SetQuietHoursRequest, opts ...grpc.CallOption) (*QuietHours, error)
+	DeleteQuietHours(ctx context.Context, in *DeleteQuietHoursRequest, opts ...grpc.CallOption) (*DeleteQuietHoursResponse, error)
+	// Дайджест: низкоприоритетные события собираются в периодическую сводку.
+	GetDigest(ctx context.Context, in *GetDigestRequest, opts ...grpc.CallOption) (*Digest, error)
+	SetDigest(ctx context.Context, in *SetDigestRequest, opts ...grpc.CallOption) (*Digest, error)
+	DeleteDigest(ctx context.Context, in *DeleteDigestRequest, opts ...grpc.CallOption) (*DeleteDigestResponse, error)
+	// Шаблоны уведомлений по типу события и языку (Go text/template).
+	ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error)
+	SetTemplate(ctx context.Context, in *SetTemplateRequest, opts ...grpc.CallOption) (*Template, error)
+	DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest, opts ...grpc.CallOption) (*DeleteTemplateResponse, error)
+	// Webhook-подписки партнёрских систем.
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	GetWebhook(ctx context.Context, in *GetWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, in *UpdateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	// История уведомлений: события, доставленные пользователям (от новых к старым).
+	ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	// Входящие: отметки read/seen/dismissed (по id или все до момента before) и число непрочитанных.
+	MarkNotifications(ctx context.Context, in *MarkNotificationsRequest, opts ...grpc.CallOption) (*MarkNotificationsResponse, error)
+	GetUnreadCount(ctx context.Context, in *GetUnreadCountRequest, opts ...grpc.CallOption) (*GetUnreadCountResponse, error)
 }
 
 type notificationServiceClient struct {
@@ -47,11 +117,351 @@ func (c *notificationServiceClient) NotifySession(ctx context.Context, in *Notif
 	return out, nil
 }
 
+func (c *notificationServiceClient) SendNotification(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*SendNotificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendNotificationResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetUserContact(ctx context.Context, in *SetUserContactRequest, opts ...grpc.CallOption) (*SetUserContactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserContactResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SetUserContact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetUserLocale(ctx context.Context, in *SetUserLocaleRequest, opts ...grpc.CallOption) (*SetUserLocaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserLocaleResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SetUserLocale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ScheduleNotification(ctx context.Context, in *ScheduleNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledNotification)
+	err := c.cc.Invoke(ctx, NotificationService_ScheduleNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetScheduledNotification(ctx context.Context, in *GetScheduledNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledNotification)
+	err := c.cc.Invoke(ctx, NotificationService_GetScheduledNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) CancelScheduledNotification(ctx context.Context, in *CancelScheduledNotificationRequest, opts ...grpc.CallOption) (*ScheduledNotification, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledNotification)
+	err := c.cc.Invoke(ctx, NotificationService_CancelScheduledNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterDeviceResponse)
+	err := c.cc.Invoke(ctx, NotificationService_RegisterDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) UnregisterDevice(ctx context.Context, in *UnregisterDeviceRequest, opts ...grpc.CallOption) (*UnregisterDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnregisterDeviceResponse)
+	err := c.cc.Invoke(ctx, NotificationService_UnregisterDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListPreferences(ctx context.Context, in *ListPreferencesRequest, opts ...grpc.CallOption) (*ListPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPreferencesResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetPreference(ctx context.Context, in *GetPreferenceRequest, opts ...grpc.CallOption) (*Preference, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Preference)
+	err := c.cc.Invoke(ctx, NotificationService_GetPreference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetPreference(ctx context.Context, in *SetPreferenceRequest, opts ...grpc.CallOption) (*Preference, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Preference)
+	err := c.cc.Invoke(ctx, NotificationService_SetPreference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) DeletePreference(ctx context.Context, in *DeletePreferenceRequest, opts ...grpc.CallOption) (*DeletePreferenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePreferenceResponse)
+	err := c.cc.Invoke(ctx, NotificationService_DeletePreference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetQuietHours(ctx context.Context, in *GetQuietHoursRequest, opts ...grpc.CallOption) (*QuietHours, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuietHours)
+	err := c.cc.Invoke(ctx, NotificationService_GetQuietHours_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetQuietHours(ctx context.Context, in *SetQuietHoursRequest, opts ...grpc.CallOption) (*QuietHours, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuietHours)
+	err := c.cc.Invoke(ctx, NotificationService_SetQuietHours_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) DeleteQuietHours(ctx context.Context, in *DeleteQuietHoursRequest, opts ...grpc.CallOption) (*DeleteQuietHoursResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQuietHoursResponse)
+	err := c.cc.Invoke(ctx, NotificationService_DeleteQuietHours_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetDigest(ctx context.Context, in *GetDigestRequest, opts ...grpc.CallOption) (*Digest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Digest)
+	err := c.cc.Invoke(ctx, NotificationService_GetDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetDigest(ctx context.Context, in *SetDigestRequest, opts ...grpc.CallOption) (*Digest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Digest)
+	err := c.cc.Invoke(ctx, NotificationService_SetDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) DeleteDigest(ctx context.Context, in *DeleteDigestRequest, opts ...grpc.CallOption) (*DeleteDigestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDigestResponse)
+	err := c.cc.Invoke(ctx, NotificationService_DeleteDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTemplatesResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SetTemplate(ctx context.Context, in *SetTemplateRequest, opts ...grpc.CallOption) (*Template, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Template)
+	err := c.cc.Invoke(ctx, NotificationService_SetTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest, opts ...grpc.CallOption) (*DeleteTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTemplateResponse)
+	err := c.cc.Invoke(ctx, NotificationService_DeleteTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, NotificationService_CreateWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetWebhook(ctx context.Context, in *GetWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, NotificationService_GetWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) UpdateWebhook(ctx context.Context, in *UpdateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, NotificationService_UpdateWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, NotificationService_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListNotifications_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) MarkNotifications(ctx context.Context, in *MarkNotificationsRequest, opts ...grpc.CallOption) (*MarkNotificationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkNotificationsResponse)
+	err := c.cc.Invoke(ctx, NotificationService_MarkNotifications_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) GetUnreadCount(ctx context.Context, in *GetUnreadCountRequest, opts ...grpc.CallOption) (*GetUnreadCountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUnreadCountResponse)
+	err := c.cc.Invoke(ctx, NotificationService_GetUnreadCount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NotificationServiceServer is the server API for NotificationService service.
 // All implementations must embed UnimplementedNotificationServiceServer
 // for forward compatibility.
 type NotificationServiceServer interface {
 	NotifySession(context.Context, *NotifySessionRequest) (*NotifySessionResponse, error)
+	// Отправка события получателям (сессия, пользователи, регионы, роли) через общий конвейер маршрутизации, как из Kafka.
+	SendNotification(context.Context, *SendNotificationRequest) (*SendNotificationResponse, error)
+	SetUserContact(context.Context, *SetUserContactRequest) (*SetUserContactResponse, error)
+	SetUserLocale(context.Context, *SetUserLocaleRequest) (*SetUserLocaleResponse, error)
+	// Отложенные уведомления: отправляются в deliver_at (или через delay) тем же конвейером, что события из Kafka.
+	ScheduleNotification(context.Context, *ScheduleNotificationRequest) (*ScheduledNotification, error)
+	GetScheduledNotification(context.Context, *GetScheduledNotificationRequest) (*ScheduledNotification, error)
+	CancelScheduledNotification(context.Context, *CancelScheduledNotificationRequest) (*ScheduledNotification, error)
+	// Push-токены устройств пользователя.
+	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceResponse, error)
+	UnregisterDevice(context.Context, *UnregisterDeviceRequest) (*UnregisterDeviceResponse, error)
+	// Настройки уведомлений пользователя по типам событий ("*" — по умолчанию).
+	ListPreferences(context.Context, *ListPreferencesRequest) (*ListPreferencesResponse, error)
+	GetPreference(context.Context, *GetPreferenceRequest) (*Preference, error)
+	SetPreference(context.Context, *SetPreferenceRequest) (*Preference, error)
+	DeletePreference(context.Context, *DeletePreferenceRequest) (*DeletePreferenceResponse, error)
+	// Окно тишины: некритичные push/email откладываются до его окончания.
+	GetQuietHours(context.Context, *GetQuietHoursRequest) (*QuietHours, error)
+	SetQuietHours(context.Context, *SetQuietHoursRequest) (*QuietHours, error)
+	DeleteQuietHours(context.Context, *DeleteQuietHoursRequest) (*DeleteQuietHoursResponse, error)
+	// Дайджест: низкоприоритетные события собираются в периодическую сводку.
+	GetDigest(context.Context, *GetDigestRequest) (*Digest, error)
+	SetDigest(context.Context, *SetDigestRequest) (*Digest, error)
+	DeleteDigest(context.Context, *DeleteDigestRequest) (*DeleteDigestResponse, error)
+	// Шаблоны уведомлений по типу события и языку (Go text/template).
+	ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error)
+	SetTemplate(context.Context, *SetTemplateRequest) (*Template, error)
+	DeleteTemplate(context.Context, *DeleteTemplateRequest) (*DeleteTemplateResponse, error)
+	// Webhook-подписки партнёрских систем.
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	GetWebhook(context.Context, *GetWebhookRequest) (*Webhook, error)
+	UpdateWebhook(context.Context, *UpdateWebhookRequest) (*Webhook, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// История уведомлений: события, доставленные пользователям (от новых к старым).
+	ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	// Входящие: отметки read/seen/dismissed (по id или все до момента before) и число непрочитанных.
+	MarkNotifications(context.Context, *MarkNotificationsRequest) (*MarkNotificationsResponse, error)
+	GetUnreadCount(context.Context, *GetUnreadCountRequest) (*GetUnreadCountResponse, error)
 	mustEmbedUnimplementedNotificationServiceServer()
 }
 
@@ -65,6 +475,96 @@ type UnimplementedNotificationServiceServer struct{}
 func (UnimplementedNotificationServiceServer) NotifySession(context.Context, *NotifySessionRequest) (*NotifySessionResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method NotifySession not implemented")
 }
+func (UnimplementedNotificationServiceServer) SendNotification(context.Context, *SendNotificationRequest) (*SendNotificationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetUserContact(context.Context, *SetUserContactRequest) (*SetUserContactResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetUserContact not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetUserLocale(context.Context, *SetUserLocaleRequest) (*SetUserLocaleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetUserLocale not implemented")
+}
+func (UnimplementedNotificationServiceServer) ScheduleNotification(context.Context, *ScheduleNotificationRequest) (*ScheduledNotification, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScheduleNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetScheduledNotification(context.Context, *GetScheduledNotificationRequest) (*ScheduledNotification, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetScheduledNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) CancelScheduledNotification(context.Context, *CancelScheduledNotificationRequest) (*ScheduledNotification, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelScheduledNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterDevice not implemented")
+}
+func (UnimplementedNotificationServiceServer) UnregisterDevice(context.Context, *UnregisterDeviceRequest) (*UnregisterDeviceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnregisterDevice not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListPreferences(context.Context, *ListPreferencesRequest) (*ListPreferencesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPreferences not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetPreference(context.Context, *GetPreferenceRequest) (*Preference, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPreference not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetPreference(context.Context, *SetPreferenceRequest) (*Preference, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetPreference not implemented")
+}
+func (UnimplementedNotificationServiceServer) DeletePreference(context.Context, *DeletePreferenceRequest) (*DeletePreferenceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeletePreference not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetQuietHours(context.Context, *GetQuietHoursRequest) (*QuietHours, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetQuietHours not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetQuietHours(context.Context, *SetQuietHoursRequest) (*QuietHours, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetQuietHours not implemented")
+}
+func (UnimplementedNotificationServiceServer) DeleteQuietHours(context.Context, *DeleteQuietHoursRequest) (*DeleteQuietHoursResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteQuietHours not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetDigest(context.Context, *GetDigestRequest) (*Digest, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDigest not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetDigest(context.Context, *SetDigestRequest) (*Digest, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetDigest not implemented")
+}
+func (UnimplementedNotificationServiceServer) DeleteDigest(context.Context, *DeleteDigestRequest) (*DeleteDigestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteDigest not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTemplates not implemented")
+}
+func (UnimplementedNotificationServiceServer) SetTemplate(context.Context, *SetTemplateRequest) (*Template, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetTemplate not implemented")
+}
+func (UnimplementedNotificationServiceServer) DeleteTemplate(context.Context, *DeleteTemplateRequest) (*DeleteTemplateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTemplate not implemented")
+}
+func (UnimplementedNotificationServiceServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetWebhook(context.Context, *GetWebhookRequest) (*Webhook, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetWebhook not implemented")
+}
+func (UnimplementedNotificationServiceServer) UpdateWebhook(context.Context, *UpdateWebhookRequest) (*Webhook, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateWebhook not implemented")
+}
+func (UnimplementedNotificationServiceServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListNotifications not implemented")
+}
+func (UnimplementedNotificationServiceServer) MarkNotifications(context.Context, *MarkNotificationsRequest) (*MarkNotificationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MarkNotifications not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetUnreadCount(context.Context, *GetUnreadCountRequest) (*GetUnreadCountResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUnreadCount not implemented")
+}
 func (UnimplementedNotificationServiceServer) mustEmbedUnimplementedNotificationServiceServer() {}
 func (UnimplementedNotificationServiceServer) testEmbeddedByValue()                             {}
 
@@ -104,16 +604,937 @@ func _NotificationService_NotifySession_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
-// NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var NotificationService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "notification_service.NotificationService",
-	HandlerType: (*NotificationServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "NotifySession",
-			Handler:    _NotificationService_NotifySession_Handler,
+func _NotificationService_SendNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendNotification(ctx, req.(*SendNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetUserContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetUserContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetUserContact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetUserContact(ctx, req.(*SetUserContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetUserLocale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserLocaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetUserLocale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetUserLocale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetUserLocale(ctx, req.(*SetUserLocaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ScheduleNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ScheduleNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ScheduleNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ScheduleNotification(ctx, req.(*ScheduleNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetScheduledNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScheduledNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetScheduledNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetScheduledNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetScheduledNotification(ctx, req.(*GetScheduledNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_CancelScheduledNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).CancelScheduledNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_CancelScheduledNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).CancelScheduledNotification(ctx, req.(*CancelScheduledNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_RegisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).RegisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_RegisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).RegisterDevice(ctx, req.(*RegisterDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_UnregisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).UnregisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_UnregisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).UnregisterDevice(ctx, req.(*UnregisterDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListPreferences(ctx, req.(*ListPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetPreference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPreferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetPreference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetPreference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetPreference(ctx, req.(*GetPreferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetPreference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPreferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetPreference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetPreference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetPreference(ctx, req.(*SetPreferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_DeletePreference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePreferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).DeletePreference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_DeletePreference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).DeletePreference(ctx, req.(*DeletePreferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetQuietHours_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuietHoursRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetQuietHours(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetQuietHours_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetQuietHours(ctx, req.(*GetQuietHoursRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetQuietHours_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuietHoursRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetQuietHours(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetQuietHours_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetQuietHours(ctx, req.(*SetQuietHoursRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_DeleteQuietHours_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuietHoursRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).DeleteQuietHours(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_DeleteQuietHours_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).DeleteQuietHours(ctx, req.(*DeleteQuietHoursRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetDigest(ctx, req.(*GetDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetDigest(ctx, req.(*SetDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_DeleteDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).DeleteDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_DeleteDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).DeleteDigest(ctx, req.(*DeleteDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListTemplates(ctx, req.(*ListTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SetTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SetTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SetTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SetTemplate(ctx, req.(*SetTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_DeleteTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).DeleteTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_DeleteTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).DeleteTemplate(ctx, req.(*DeleteTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetWebhook(ctx, req.(*GetWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_UpdateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).UpdateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_UpdateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).UpdateWebhook(ctx, req.(*UpdateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_MarkNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).MarkNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_MarkNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).MarkNotifications(ctx, req.(*MarkNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_GetUnreadCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnreadCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetUnreadCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetUnreadCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetUnreadCount(ctx, req.(*GetUnreadCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NotificationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "notification_service.NotificationService",
+	HandlerType: (*NotificationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NotifySession",
+			Handler:    _NotificationService_NotifySession_Handler,
+		},
+		{
+			MethodName: "SendNotification",
+			Handler:    _NotificationService_SendNotification_Handler,
+		},
+		{
+			MethodName: "SetUserContact",
+			Handler:    _NotificationService_SetUserContact_Handler,
+		},
+		{
+			MethodName: "SetUserLocale",
+			Handler:    _NotificationService_SetUserLocale_Handler,
+		},
+		{
+			MethodName: "ScheduleNotification",
+			Handler:    _NotificationService_ScheduleNotification_Handler,
+		},
+		{
+			MethodName: "GetScheduledNotification",
+			Handler:    _NotificationService_GetScheduledNotification_Handler,
+		},
+		{
+			MethodName: "CancelScheduledNotification",
+			Handler:    _NotificationService_CancelScheduledNotification_Handler,
+		},
+		{
+			MethodName: "RegisterDevice",
+			Handler:    _NotificationService_RegisterDevice_Handler,
+		},
+		{
+			MethodName: "UnregisterDevice",
+			Handler:    _NotificationService_UnregisterDevice_Handler,
+		},
+		{
+			MethodName: "ListPreferences",
+			Handler:    _NotificationService_ListPreferences_Handler,
+		},
+		{
+			MethodName: "GetPreference",
+			Handler:    _NotificationService_GetPreference_Handler,
+		},
+		{
+			MethodName: "SetPreference",
+			Handler:    _NotificationService_SetPreference_Handler,
+		},
+		{
+			MethodName: "DeletePreference",
+			Handler:    _NotificationService_DeletePreference_Handler,
+		},
+		{
+			MethodName: "GetQuietHours",
+			Handler:    _NotificationService_GetQuietHours_Handler,
+		},
+		{
+			MethodName: "SetQuietHours",
+			Handler:    _NotificationService_SetQuietHours_Handler,
+		},
+		{
+			MethodName: "DeleteQuietHours",
+			Handler:    _NotificationService_DeleteQuietHours_Handler,
+		},
+		{
+			MethodName: "GetDigest",
+			Handler:    _NotificationService_GetDigest_Handler,
+		},
+		{
+			MethodName: "SetDigest",
+			Handler:    _NotificationService_SetDigest_Handler,
+		},
+		{
+			MethodName: "DeleteDigest",
+			Handler:    _NotificationService_DeleteDigest_Handler,
+		},
+		{
+			MethodName: "ListTemplates",
+			Handler:    _NotificationService_ListTemplates_Handler,
+		},
+		{
+			MethodName: "SetTemplate",
+			Handler:    _NotificationService_SetTemplate_Handler,
+		},
+		{
+			MethodName: "DeleteTemplate",
+			Handler:    _NotificationService_DeleteTemplate_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _NotificationService_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _NotificationService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "GetWebhook",
+			Handler:    _NotificationService_GetWebhook_Handler,
+		},
+		{
+			MethodName: "UpdateWebhook",
+			Handler:    _NotificationService_UpdateWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _NotificationService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _NotificationService_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "ListNotifications",
+			Handler:    _NotificationService_ListNotifications_Handler,
+		},
+		{
+			MethodName: "MarkNotifications",
+			Handler:    _NotificationService_MarkNotifications_Handler,
+		},
+		{
+			MethodName: "GetUnreadCount",
+			Handler:    _NotificationService_GetUnreadCount_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "notification.proto",
+}
+
+const (
+	NotificationAdminService_ListConnections_FullMethodName        = "/notification_service.NotificationAdminService/ListConnections"
+	NotificationAdminService_DisconnectConnection_FullMethodName   = "/notification_service.NotificationAdminService/DisconnectConnection"
+	NotificationAdminService_DisconnectUser_FullMethodName         = "/notification_service.NotificationAdminService/DisconnectUser"
+	NotificationAdminService_ListSessionSubscribers_FullMethodName = "/notification_service.NotificationAdminService/ListSessionSubscribers"
+	NotificationAdminService_UnsubscribeSession_FullMethodName     = "/notification_service.NotificationAdminService/UnsubscribeSession"
+)
+
+// NotificationAdminServiceClient is the client API for NotificationAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NotificationAdminService — подключения WebSocket этой реплики для поддержки.
+// Требует заголовок authorization: Bearer <ADMIN_TOKEN>.
+type NotificationAdminServiceClient interface {
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	DisconnectConnection(ctx context.Context, in *DisconnectConnectionRequest, opts ...grpc.CallOption) (*DisconnectResponse, error)
+	DisconnectUser(ctx context.Context, in *DisconnectUserRequest, opts ...grpc.CallOption) (*DisconnectResponse, error)
+	ListSessionSubscribers(ctx context.Context, in *ListSessionSubscribersRequest, opts ...grpc.CallOption) (*ListSessionSubscribersResponse, error)
+	UnsubscribeSession(ctx context.Context, in *UnsubscribeSessionRequest, opts ...grpc.CallOption) (*UnsubscribeSessionResponse, error)
+}
+
+type notificationAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNotificationAdminServiceClient(cc grpc.ClientConnInterface) NotificationAdminServiceClient {
+	return &notificationAdminServiceClient{cc}
+}
+
+func (c *notificationAdminServiceClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, NotificationAdminService_ListConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationAdminServiceClient) DisconnectConnection(ctx context.Context, in *DisconnectConnectionRequest, opts ...grpc.CallOption) (*DisconnectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectResponse)
+	err := c.cc.Invoke(ctx, NotificationAdminService_DisconnectConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationAdminServiceClient) DisconnectUser(ctx context.Context, in *DisconnectUserRequest, opts ...grpc.CallOption) (*DisconnectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectResponse)
+	err := c.cc.Invoke(ctx, NotificationAdminService_DisconnectUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationAdminServiceClient) ListSessionSubscribers(ctx context.Context, in *ListSessionSubscribersRequest, opts ...grpc.CallOption) (*ListSessionSubscribersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionSubscribersResponse)
+	err := c.cc.Invoke(ctx, NotificationAdminService_ListSessionSubscribers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationAdminServiceClient) UnsubscribeSession(ctx context.Context, in *UnsubscribeSessionRequest, opts ...grpc.CallOption) (*UnsubscribeSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribeSessionResponse)
+	err := c.cc.Invoke(ctx, NotificationAdminService_UnsubscribeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NotificationAdminServiceServer is the server API for NotificationAdminService service.
+// All implementations must embed UnimplementedNotificationAdminServiceServer
+// for forward compatibility.
+//
+// NotificationAdminService — подключения WebSocket этой реплики для поддержки.
+// Требует заголовок authorization: Bearer <ADMIN_TOKEN>.
+type NotificationAdminServiceServer interface {
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	DisconnectConnection(context.Context, *DisconnectConnectionRequest) (*DisconnectResponse, error)
+	DisconnectUser(context.Context, *DisconnectUserRequest) (*DisconnectResponse, error)
+	ListSessionSubscribers(context.Context, *ListSessionSubscribersRequest) (*ListSessionSubscribersResponse, error)
+	UnsubscribeSession(context.Context, *UnsubscribeSessionRequest) (*UnsubscribeSessionResponse, error)
+	mustEmbedUnimplementedNotificationAdminServiceServer()
+}
+
+// UnimplementedNotificationAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNotificationAdminServiceServer struct{}
+
+func (UnimplementedNotificationAdminServiceServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListConnections not implemented")
+}
+func (UnimplementedNotificationAdminServiceServer) DisconnectConnection(context.Context, *DisconnectConnectionRequest) (*DisconnectResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DisconnectConnection not implemented")
+}
+func (UnimplementedNotificationAdminServiceServer) DisconnectUser(context.Context, *DisconnectUserRequest) (*DisconnectResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DisconnectUser not implemented")
+}
+func (UnimplementedNotificationAdminServiceServer) ListSessionSubscribers(context.Context, *ListSessionSubscribersRequest) (*ListSessionSubscribersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessionSubscribers not implemented")
+}
+func (UnimplementedNotificationAdminServiceServer) UnsubscribeSession(context.Context, *UnsubscribeSessionRequest) (*UnsubscribeSessionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnsubscribeSession not implemented")
+}
+func (UnimplementedNotificationAdminServiceServer) mustEmbedUnimplementedNotificationAdminServiceServer() {
+}
+func (UnimplementedNotificationAdminServiceServer) testEmbeddedByValue() {}
+
+// UnsafeNotificationAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NotificationAdminServiceServer will
+// result in compilation errors.
+type UnsafeNotificationAdminServiceServer interface {
+	mustEmbedUnimplementedNotificationAdminServiceServer()
+}
+
+func RegisterNotificationAdminServiceServer(s grpc.ServiceRegistrar, srv NotificationAdminServiceServer) {
+	// If the following call panics, it indicates UnimplementedNotificationAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NotificationAdminService_ServiceDesc, srv)
+}
+
+func _NotificationAdminService_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationAdminServiceServer).ListConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationAdminService_ListConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationAdminServiceServer).ListConnections(ctx, req.(*ListConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationAdminService_DisconnectConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationAdminServiceServer).DisconnectConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationAdminService_DisconnectConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationAdminServiceServer).DisconnectConnection(ctx, req.(*DisconnectConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationAdminService_DisconnectUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationAdminServiceServer).DisconnectUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationAdminService_DisconnectUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationAdminServiceServer).DisconnectUser(ctx, req.(*DisconnectUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationAdminService_ListSessionSubscribers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionSubscribersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationAdminServiceServer).ListSessionSubscribers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationAdminService_ListSessionSubscribers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationAdminServiceServer).ListSessionSubscribers(ctx, req.(*ListSessionSubscribersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationAdminService_UnsubscribeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationAdminServiceServer).UnsubscribeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationAdminService_UnsubscribeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationAdminServiceServer).UnsubscribeSession(ctx, req.(*UnsubscribeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NotificationAdminService_ServiceDesc is the grpc.ServiceDesc for NotificationAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NotificationAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "notification_service.NotificationAdminService",
+	HandlerType: (*NotificationAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListConnections",
+			Handler:    _NotificationAdminService_ListConnections_Handler,
+		},
+		{
+			MethodName: "DisconnectConnection",
+			Handler:    _NotificationAdminService_DisconnectConnection_Handler,
+		},
+		{
+			MethodName: "DisconnectUser",
+			Handler:    _NotificationAdminService_DisconnectUser_Handler,
+		},
+		{
+			MethodName: "ListSessionSubscribers",
+			Handler:    _NotificationAdminService_ListSessionSubscribers_Handler,
+		},
+		{
+			MethodName: "UnsubscribeSession",
+			Handler:    _NotificationAdminService_UnsubscribeSession_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},